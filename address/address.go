@@ -0,0 +1,109 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Size of the public key hash an address encodes.
+const HASH_SIZE = 20
+
+// Size of the checksum appended to an address.
+const CHECKSUM_SIZE = 4
+
+// Versions of addresses, which decide how outputs pay to their hash.
+const (
+	// Pay to the hash of a public key.
+	VERSION_P2PKH = 0x00
+	// Pay to the hash of a redeem script, e.g. a multisig script.
+	VERSION_P2SH = 0x05
+)
+
+// Alphabet of Base58, which leaves out 0, O, I and l that are easily confused.
+const BASE58_ALPHABET = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() map[byte]int64 {
+	m := make(map[byte]int64)
+	for i := 0; i < len(BASE58_ALPHABET); i++ {
+		m[BASE58_ALPHABET[i]] = int64(i)
+	}
+	return m
+}()
+
+// Encode bytes in Base58. Every leading zero byte is encoded as a leading '1'.
+func EncodeBase58(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(int64(len(BASE58_ALPHABET)))
+	mod := new(big.Int)
+	res := []byte{}
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		res = append(res, BASE58_ALPHABET[mod.Int64()])
+	}
+	for i := 0; i < len(b) && b[i] == 0; i++ {
+		res = append(res, BASE58_ALPHABET[0])
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return string(res)
+}
+
+// Decode a Base58 string.
+func DecodeBase58(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(int64(len(BASE58_ALPHABET)))
+	for i := 0; i < len(s); i++ {
+		v, ok := base58Index[s[i]]
+		if !ok {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(v))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == BASE58_ALPHABET[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// First CHECKSUM_SIZE bytes of the double SHA256 of data.
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:CHECKSUM_SIZE]
+}
+
+// Encode the version and public key hash into an address in Base58Check, i.e. Base58 of
+// version, hash and the checksum of both.
+func Encode(version byte, hash []byte) string {
+	data := append([]byte{version}, hash...)
+	return EncodeBase58(append(data, checksum(data)...))
+}
+
+// Decode an address into its version and public key hash. A typo is almost always
+// caught by the checksum.
+func Decode(s string) (byte, []byte, error) {
+	data, err := DecodeBase58(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) != 1+HASH_SIZE+CHECKSUM_SIZE {
+		return 0, nil, fmt.Errorf("address must be %d bytes, got %d", 1+HASH_SIZE+CHECKSUM_SIZE, len(data))
+	}
+	payload := data[:1+HASH_SIZE]
+	if !bytes.Equal(checksum(payload), data[1+HASH_SIZE:]) {
+		return 0, nil, errors.New("address checksum mismatch, please check for typos")
+	}
+	return payload[0], payload[1:], nil
+}
+
+// Whether s is a well formed address.
+func IsValid(s string) bool {
+	_, _, err := Decode(s)
+	return err == nil
+}
@@ -0,0 +1,48 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase58(t *testing.T) {
+	assert.Equal(t, "", EncodeBase58([]byte{}))
+	assert.Equal(t, "11", EncodeBase58([]byte{0, 0}))
+	// Test vector from the Base58 draft.
+	assert.Equal(t, "2NEpo7TZRRrLZSi2U", EncodeBase58([]byte("Hello World!")))
+	b, err := DecodeBase58("112NEpo7TZRRrLZSi2U")
+	assert.Nil(t, err)
+	assert.Equal(t, append([]byte{0, 0}, []byte("Hello World!")...), b)
+	_, err = DecodeBase58("0OIl")
+	assert.NotNil(t, err)
+}
+
+func TestEncodeAndDecode(t *testing.T) {
+	hash := make([]byte, HASH_SIZE)
+	for i := range hash {
+		hash[i] = byte(i * 13)
+	}
+	for _, version := range []byte{0, 1, 255} {
+		addr := Encode(version, hash)
+		assert.True(t, IsValid(addr))
+		v, h, err := Decode(addr)
+		assert.Nil(t, err)
+		assert.Equal(t, version, v)
+		assert.Equal(t, hash, h)
+	}
+
+	// Any single character typo is caught.
+	addr := Encode(1, hash)
+	for i := 0; i < len(addr); i++ {
+		for j := 0; j < len(BASE58_ALPHABET); j++ {
+			if BASE58_ALPHABET[j] == addr[i] {
+				continue
+			}
+			typo := addr[:i] + string(BASE58_ALPHABET[j]) + addr[i+1:]
+			assert.False(t, IsValid(typo), typo)
+		}
+	}
+	assert.False(t, IsValid(addr[1:]))
+	assert.False(t, IsValid(Encode(1, hash[1:])))
+}
@@ -7,14 +7,33 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/Luismorlan/btc_in_go/address"
 )
 
+// A positive amount in coins, e.g. "1", "1.5" or ".00000001".
+var AMOUNT_REGEX = regexp.MustCompile(`^([0-9]+|[0-9]*\.[0-9]{1,8})$`)
+
+// An alias of an address, which is too short to be mistaken for an address.
+var ALIAS_REGEX = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,15}$`)
+
+// Optional fee argument of transfer, either "fee=AMOUNT" with the fee in coins, or
+// "rate=N" with the fee rate in base units per 1000 bytes.
+var FEE_ARG_REGEX = regexp.MustCompile(`^(fee=([0-9]+|[0-9]*\.[0-9]{1,8})|rate=[0-9]+)$`)
+
+// Optional fee rate argument "rate=N" in base units per 1000 bytes, for commands whose
+// fee depends on the size of the transaction they build.
+var FEE_RATE_ARG_REGEX = regexp.MustCompile(`^rate=[0-9]+$`)
+
+// A SHA256 hash or a secret of a hash time locked contract in hex, which are both 32 bytes.
+var HASH_REGEX = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
 const (
 	// do nothing operation
 	NOOP = iota
 	// Initiate a money transfer from wallet
 	TRANSFER
-	// Print user public key
+	// Print user address and public key
 	MY_PK
 	// Connect a full node with ip address and port
 	CONNECT
@@ -24,6 +43,30 @@ const (
 	ALIAS
 	// Show alias
 	SHOW_ALIAS
+	// Rebroadcast a pending transfer with a higher fee
+	BUMP_FEE
+	// Move all coins to a new Ed25519 key
+	MIGRATE_KEY
+	// Create a multisig address
+	MULTISIG
+	// Create a partially signed transaction spending from a multisig address
+	PSBT_CREATE
+	// Sign a partially signed transaction
+	PSBT_SIGN
+	// Merge signatures of partially signed transactions
+	PSBT_COMBINE
+	// Send a partially signed transaction with enough signatures
+	PSBT_SEND
+	// Generate a secret for a hash time locked contract
+	HTLC_SECRET
+	// Fund a hash time locked contract
+	HTLC_CREATE
+	// Claim a hash time locked contract with its secret
+	HTLC_CLAIM
+	// Take back a hash time locked contract after its lock time
+	HTLC_REFUND
+	// Find the secret revealed by claiming a hash time locked contract
+	HTLC_PREIMAGE
 )
 
 type ClientCommand struct {
@@ -34,16 +77,110 @@ type ClientCommand struct {
 func (c ClientCommand) IsValid() bool {
 	switch c.Op {
 	case TRANSFER:
+		if len(c.Args) != 2 && len(c.Args) != 3 {
+			return false
+		}
+		if len(c.Args) == 3 && !FEE_ARG_REGEX.MatchString(c.Args[2]) {
+			return false
+		}
+		// Receiver is an alias or an address, whose checksum catches typos.
+		if !ALIAS_REGEX.MatchString(c.Args[0]) && !address.IsValid(c.Args[0]) {
+			return false
+		}
+		// Amount is in coins with at most 8 decimals.
+		value := c.Args[1]
+		return AMOUNT_REGEX.MatchString(value) && strings.Trim(value, "0.") != ""
+	case BUMP_FEE:
 		if len(c.Args) != 2 {
 			return false
 		}
+		if _, err := hex.DecodeString(c.Args[0]); err != nil {
+			return false
+		}
+		value := c.Args[1]
+		return AMOUNT_REGEX.MatchString(value) && strings.Trim(value, "0.") != ""
+	case MIGRATE_KEY, PSBT_SIGN, PSBT_SEND:
+		return len(c.Args) == 1 && c.Args[0] != ""
+	case PSBT_COMBINE:
+		if len(c.Args) < 2 {
+			return false
+		}
+		for _, path := range c.Args {
+			if path == "" {
+				return false
+			}
+		}
+		return true
+	case MULTISIG:
+		// Number of signatures required, followed by the public keys in hex.
+		if len(c.Args) < 2 {
+			return false
+		}
+		m, err := strconv.Atoi(c.Args[0])
+		if err != nil || m < 1 || m > len(c.Args)-1 {
+			return false
+		}
+		for _, pk := range c.Args[1:] {
+			if _, err := hex.DecodeString(pk); err != nil || pk == "" {
+				return false
+			}
+		}
+		return true
+	case PSBT_CREATE:
+		if len(c.Args) != 4 && len(c.Args) != 5 {
+			return false
+		}
+		if len(c.Args) == 5 && !FEE_ARG_REGEX.MatchString(c.Args[4]) {
+			return false
+		}
+		for _, addr := range c.Args[:2] {
+			if !ALIAS_REGEX.MatchString(addr) && !address.IsValid(addr) {
+				return false
+			}
+		}
+		value := c.Args[2]
+		return AMOUNT_REGEX.MatchString(value) && strings.Trim(value, "0.") != "" && c.Args[3] != ""
+	case HTLC_CREATE:
+		if len(c.Args) != 4 && len(c.Args) != 5 {
+			return false
+		}
+		if len(c.Args) == 5 && !FEE_ARG_REGEX.MatchString(c.Args[4]) {
+			return false
+		}
+		if !ALIAS_REGEX.MatchString(c.Args[0]) && !address.IsValid(c.Args[0]) {
+			return false
+		}
 		value := c.Args[1]
-		v, err := strconv.ParseFloat(value, 64)
-		if err != nil {
+		if !AMOUNT_REGEX.MatchString(value) || strings.Trim(value, "0.") == "" {
 			return false
 		}
-		return err == nil && v > 0
-	case MY_PK, GET_BALANCE, SHOW_ALIAS:
+		lockTime, err := strconv.ParseInt(c.Args[3], 10, 64)
+		return HASH_REGEX.MatchString(c.Args[2]) && err == nil && lockTime > 0
+	case HTLC_CLAIM:
+		if len(c.Args) != 2 && len(c.Args) != 3 {
+			return false
+		}
+		if len(c.Args) == 3 && !FEE_RATE_ARG_REGEX.MatchString(c.Args[2]) {
+			return false
+		}
+		_, err := hex.DecodeString(c.Args[0])
+		return err == nil && c.Args[0] != "" && HASH_REGEX.MatchString(c.Args[1])
+	case HTLC_REFUND:
+		if len(c.Args) != 1 && len(c.Args) != 2 {
+			return false
+		}
+		if len(c.Args) == 2 && !FEE_RATE_ARG_REGEX.MatchString(c.Args[1]) {
+			return false
+		}
+		_, err := hex.DecodeString(c.Args[0])
+		return err == nil && c.Args[0] != ""
+	case HTLC_PREIMAGE:
+		if len(c.Args) != 1 {
+			return false
+		}
+		_, err := hex.DecodeString(c.Args[0])
+		return err == nil && c.Args[0] != ""
+	case MY_PK, GET_BALANCE, SHOW_ALIAS, HTLC_SECRET:
 		return len(c.Args) == 0
 	case CONNECT:
 		if len(c.Args) != 2 {
@@ -59,12 +196,7 @@ func (c ClientCommand) IsValid() bool {
 		if len(c.Args) != 2 {
 			return false
 		}
-		pk := c.Args[0]
-		_, err := hex.DecodeString(pk)
-		if err != nil {
-			return false
-		}
-		return true
+		return address.IsValid(c.Args[0]) && ALIAS_REGEX.MatchString(c.Args[1])
 	default:
 		return false
 	}
@@ -90,6 +222,30 @@ func CreateClientCommand(s string) (ClientCommand, error) {
 		cmd.Op = ALIAS
 	case "show_alias":
 		cmd.Op = SHOW_ALIAS
+	case "bump":
+		cmd.Op = BUMP_FEE
+	case "migrate_key":
+		cmd.Op = MIGRATE_KEY
+	case "multisig":
+		cmd.Op = MULTISIG
+	case "psbt_create":
+		cmd.Op = PSBT_CREATE
+	case "psbt_sign":
+		cmd.Op = PSBT_SIGN
+	case "psbt_combine":
+		cmd.Op = PSBT_COMBINE
+	case "psbt_send":
+		cmd.Op = PSBT_SEND
+	case "htlc_secret":
+		cmd.Op = HTLC_SECRET
+	case "htlc_create":
+		cmd.Op = HTLC_CREATE
+	case "htlc_claim":
+		cmd.Op = HTLC_CLAIM
+	case "htlc_refund":
+		cmd.Op = HTLC_REFUND
+	case "htlc_preimage":
+		cmd.Op = HTLC_PREIMAGE
 	default:
 		cmd.Op = NOOP
 	}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/address"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferReceiver(t *testing.T) {
+	addr := address.Encode(1, make([]byte, address.HASH_SIZE))
+	_, err := CreateClientCommand("transfer " + addr + " 1.5")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("transfer alice 1.5 fee=0.001")
+	assert.Nil(t, err)
+
+	// A typo in the address is rejected.
+	typo := []byte(addr)
+	if typo[5] == 'a' {
+		typo[5] = 'b'
+	} else {
+		typo[5] = 'a'
+	}
+	_, err = CreateClientCommand("transfer " + string(typo) + " 1.5")
+	assert.NotNil(t, err)
+
+	_, err = CreateClientCommand("alias " + addr + " alice")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("alias " + string(typo) + " alice")
+	assert.NotNil(t, err)
+	_, err = CreateClientCommand("alias " + addr + " " + addr)
+	assert.NotNil(t, err)
+}
+
+func TestMultiSigCommands(t *testing.T) {
+	_, err := CreateClientCommand("multisig 2 00aa 01bb 01cc")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("multisig 3 00aa 01bb")
+	assert.NotNil(t, err)
+	_, err = CreateClientCommand("multisig 1 xyz")
+	assert.NotNil(t, err)
+
+	addr := address.Encode(address.VERSION_P2SH, make([]byte, address.HASH_SIZE))
+	_, err = CreateClientCommand("psbt_create " + addr + " bob 1.5 /tmp/tx.psbt rate=1000")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("psbt_create " + addr + " bob 1.5")
+	assert.NotNil(t, err)
+	_, err = CreateClientCommand("psbt_combine /tmp/tx.psbt /tmp/other.psbt")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("psbt_combine /tmp/tx.psbt")
+	assert.NotNil(t, err)
+}
+
+func TestHTLCCommands(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	_, err := CreateClientCommand("htlc_secret")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("htlc_create bob 1.5 " + hash + " 100 rate=1000")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("htlc_create bob 1.5 " + hash[2:] + " 100")
+	assert.NotNil(t, err)
+	_, err = CreateClientCommand("htlc_create bob 1.5 " + hash + " 0")
+	assert.NotNil(t, err)
+	_, err = CreateClientCommand("htlc_claim 63a8 " + hash + " rate=1000")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("htlc_claim 63a8 " + hash + " fee=1")
+	assert.NotNil(t, err)
+	_, err = CreateClientCommand("htlc_refund 63a8")
+	assert.Nil(t, err)
+	_, err = CreateClientCommand("htlc_refund xyz")
+	assert.NotNil(t, err)
+	_, err = CreateClientCommand("htlc_preimage 63a8")
+	assert.Nil(t, err)
+}
@@ -31,7 +31,7 @@ const (
 	SHOW
 	// Sync the blockchain.
 	SYNC
-	// Show your public key in hex string.
+	// Show your address and public key in hex string.
 	KEY
 	// Ask a full node to return all its peers.
 	INTRODUCE
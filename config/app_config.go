@@ -2,14 +2,48 @@ package config
 
 // This is the global app config for the blockchain.
 type AppConfig struct {
-	// How many leading 0s to form a valid hash.
+	// How many leading 0s to form a valid hash. This is the initial difficulty if retargeting is enabled.
 	DIFFICULTY int `yaml:"DIFFICULTY"`
-	// The default coinbase reward.
-	COINBASE_REWARD float64 `yaml:"COINBASE_REWARD"`
+	// Difficulty is retargeted every this many blocks. Set to 0 to always use DIFFICULTY.
+	RETARGET_INTERVAL int64 `yaml:"RETARGET_INTERVAL"`
+	// Desired number of seconds between 2 blocks, which retargeting adjusts toward.
+	TARGET_BLOCK_INTERVAL int64 `yaml:"TARGET_BLOCK_INTERVAL"`
+	// Maximum number of bits the difficulty can change in one retarget.
+	MAX_RETARGET_STEP int64 `yaml:"MAX_RETARGET_STEP"`
+	// The initial coinbase reward, in base units (1 coin = 10^8 base units).
+	COINBASE_REWARD int64 `yaml:"COINBASE_REWARD"`
+	// The coinbase reward halves every this many blocks. Set to 0 to never halve.
+	HALVING_INTERVAL int64 `yaml:"HALVING_INTERVAL"`
+	// The coinbase reward never halves below this, in base units.
+	MIN_COINBASE_REWARD int64 `yaml:"MIN_COINBASE_REWARD"`
+	// Coinbase outputs can only be spent in a block at least this many blocks above the block
+	// creating them, since recent blocks are the most likely to be orphaned. Set to 0 to
+	// spend them right away.
+	COINBASE_MATURITY int64 `yaml:"COINBASE_MATURITY"`
 	// How deep a block is confirmed. Aka how many block need to be after this block to confirm a block.
 	CONFIRMATION int64 `yaml:"CONFIRMATION"`
 	// Whether or not to remine the block if tail changed in between.
 	REMINE_ON_TAIL_CHANGE bool `yaml:"REMINE_ON_TAIL_CHANGE"`
-	// Length of the RSA key, for convenienve 304 is preferred, but 2048 can give us better security.
+	// Maximum serialized size of a block in bytes. Set to 0 for no limit.
+	MAX_BLOCK_SIZE int64 `yaml:"MAX_BLOCK_SIZE"`
+	// Maximum total size in bytes of transactions in the pool, transactions paying the lowest
+	// fee rate are evicted when it's full. Set to 0 for no limit.
+	MAX_POOL_BYTES int64 `yaml:"MAX_POOL_BYTES"`
+	// Transactions staying in the pool for more than this many seconds are removed. Set to 0 to keep them forever.
+	MAX_POOL_TX_AGE int64 `yaml:"MAX_POOL_TX_AGE"`
+	// Check for expired transactions in the pool every this many seconds.
+	POOL_EXPIRY_INTERVAL int64 `yaml:"POOL_EXPIRY_INTERVAL"`
+	// Minimum fee rate in base units per 1000 bytes for a transaction to enter the pool.
+	MIN_RELAY_FEE_RATE int64 `yaml:"MIN_RELAY_FEE_RATE"`
+	// Maximum number of orphan blocks, whose parent is unknown, kept in memory.
+	MAX_ORPHAN_BLOCKS int `yaml:"MAX_ORPHAN_BLOCKS"`
+	// Maximum total size in bytes of orphan blocks kept in memory.
+	MAX_ORPHAN_BYTES int64 `yaml:"MAX_ORPHAN_BYTES"`
+	// Type of the key generated when there is no key file, rsa or ed25519.
+	KEY_TYPE string `yaml:"KEY_TYPE"`
+	// Length of generated RSA keys, DEFAULT_RSA_LEN if not set.
 	RSA_LEN int64 `yaml:"RSA_LEN"`
+	// Hash of the genesis block in hex, up to 32 bytes, which tells independent networks
+	// apart. model.GENESIS_HASH if not set.
+	GENESIS_HASH string `yaml:"GENESIS_HASH"`
 }
@@ -0,0 +1,165 @@
+package full_node
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Luismorlan/btc_in_go/address"
+	"github.com/Luismorlan/btc_in_go/commands"
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"github.com/Luismorlan/btc_in_go/service"
+	"github.com/Luismorlan/btc_in_go/storage"
+	"github.com/Luismorlan/btc_in_go/utils"
+	"github.com/Luismorlan/btc_in_go/wallet"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// Start a full node of the network with the genesis hash serving on a local port, which
+// mines to the key in keyPath.
+func startTestServer(t *testing.T, genesis string, keyPath string) (*FullNodeServer, string) {
+	c := createTestConfig()
+	c.GENESIS_HASH = genesis
+	c.CONFIRMATION = 1
+	c.RETARGET_INTERVAL = 0
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	assert.Nil(t, err)
+	sev := NewFullNodeServer(c, []Peer{}, Address{IpAddr: "127.0.0.1", Port: port}, keyPath, storage.NewMemoryBlockStore(), make(chan commands.Command), nil)
+	grpcServer := grpc.NewServer()
+	service.RegisterFullNodeServiceServer(grpcServer, sev)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+	return sev, port
+}
+
+// Return a wallet of the key in keyPath connected to the full node on port.
+func createTestWallet(t *testing.T, keyPath string, port string) *wallet.Wallet {
+	w := wallet.NewWallet(keyPath, model.KeyType_ED25519, nil)
+	assert.Nil(t, w.SetFullNodeConnection("127.0.0.1", port))
+	assert.Eventually(t, func() bool {
+		_, _, err := w.GetTotalDeposit()
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return w
+}
+
+func mineTestBlocks(t *testing.T, sev *FullNodeServer, n int) {
+	for i := 0; i < n; i++ {
+		_, err := sev.Mine(make(chan commands.Command))
+		assert.Nil(t, err)
+	}
+}
+
+func TestAtomicSwap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic_swap")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	aliceKey, bobKey := filepath.Join(dir, "alice.pem"), filepath.Join(dir, "bob.pem")
+
+	// Alice mines on network A and Bob on network B, which never accept each other's blocks.
+	sevA, portA := startTestServer(t, "0a", aliceKey)
+	sevB, portB := startTestServer(t, "0b", bobKey)
+	mineTestBlocks(t, sevA, 2)
+	mineTestBlocks(t, sevB, 2)
+	aliceA, bobA := createTestWallet(t, aliceKey, portA), createTestWallet(t, bobKey, portA)
+	aliceB, bobB := createTestWallet(t, aliceKey, portB), createTestWallet(t, bobKey, portB)
+
+	// Alice locks 0.5 coin to Bob on A, refundable from height 20.
+	preimage, hash, err := utils.NewHTLCSecret()
+	assert.Nil(t, err)
+	addrA, redeemA, err := aliceA.CreateHTLC(bobA.GetAddress(), hash, 20)
+	assert.Nil(t, err)
+	_, err = aliceA.TransferMoneyWithFeeRate(addrA, utils.COIN/2, 1000)
+	assert.Nil(t, err)
+	mineTestBlocks(t, sevA, 2)
+
+	// Bob checks the contract pays to him with the hash, and locks 0.8 coin to Alice on B
+	// with a shorter lock time.
+	h, recipient, lockTime, _, ok := script.ExtractHashTimeLock(redeemA)
+	assert.True(t, ok)
+	_, bobHash, err := address.Decode(bobA.GetAddress())
+	assert.Nil(t, err)
+	assert.Equal(t, hash, h)
+	assert.Equal(t, bobHash, recipient)
+	assert.Equal(t, int64(20), lockTime)
+	assert.Equal(t, utils.GetScriptAddress(redeemA), addrA)
+	addrB, redeemB, err := bobB.CreateHTLC(aliceB.GetAddress(), hash, 10)
+	assert.Nil(t, err)
+	_, err = bobB.TransferMoneyWithFeeRate(addrB, utils.COIN*8/10, 1000)
+	assert.Nil(t, err)
+	mineTestBlocks(t, sevB, 2)
+
+	// Neither can take the other's coins without the secret, nor before the lock time.
+	_, err = bobB.FindHTLCPreimage(redeemB)
+	assert.NotNil(t, err)
+	_, err = bobA.ClaimHTLC(redeemA, hash, 1000)
+	assert.NotNil(t, err)
+	_, err = aliceA.RefundHTLC(redeemA, 1000)
+	assert.NotNil(t, err)
+
+	// Alice claims on B, which reveals the secret to Bob.
+	_, err = aliceB.ClaimHTLC(redeemB, preimage, 1000)
+	assert.Nil(t, err)
+	revealed, err := bobB.FindHTLCPreimage(redeemB)
+	assert.Nil(t, err)
+	assert.Equal(t, preimage, revealed)
+	mineTestBlocks(t, sevB, 2)
+	revealed, err = bobB.FindHTLCPreimage(redeemB)
+	assert.Nil(t, err)
+	assert.Equal(t, preimage, revealed)
+
+	_, err = bobA.ClaimHTLC(redeemA, revealed, 1000)
+	assert.Nil(t, err)
+	mineTestBlocks(t, sevA, 2)
+
+	aliceBalance, _, err := aliceB.GetTotalDeposit()
+	assert.Nil(t, err)
+	assert.True(t, aliceBalance > 0 && aliceBalance < utils.COIN*8/10)
+	bobBalance, _, err := bobA.GetTotalDeposit()
+	assert.Nil(t, err)
+	assert.True(t, bobBalance > 0 && bobBalance < utils.COIN/2)
+	_, err = bobA.ClaimHTLC(redeemA, revealed, 1000)
+	assert.NotNil(t, err)
+}
+
+func TestHTLCRefund(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic_swap")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	aliceKey, bobKey := filepath.Join(dir, "alice.pem"), filepath.Join(dir, "bob.pem")
+	sev, port := startTestServer(t, "0a", aliceKey)
+	mineTestBlocks(t, sev, 2)
+	alice, bob := createTestWallet(t, aliceKey, port), createTestWallet(t, bobKey, port)
+
+	_, hash, err := utils.NewHTLCSecret()
+	assert.Nil(t, err)
+	addr, redeem, err := alice.CreateHTLC(bob.GetAddress(), hash, 6)
+	assert.Nil(t, err)
+	_, err = alice.TransferMoneyWithFeeRate(addr, utils.COIN/2, 1000)
+	assert.Nil(t, err)
+	mineTestBlocks(t, sev, 2)
+
+	// The next block is at height 5, below the lock time.
+	_, err = alice.RefundHTLC(redeem, 1000)
+	assert.NotNil(t, err)
+	_, err = bob.RefundHTLC(redeem, 1000)
+	assert.NotNil(t, err)
+	mineTestBlocks(t, sev, 1)
+	txs, err := alice.RefundHTLC(redeem, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(txs))
+	assert.Equal(t, int64(6), txs[0].LockTime)
+	mineTestBlocks(t, sev, 2)
+
+	_, err = alice.RefundHTLC(redeem, 1000)
+	assert.NotNil(t, err)
+	_, err = bob.FindHTLCPreimage(redeem)
+	assert.NotNil(t, err)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/Luismorlan/btc_in_go/full_node"
 	"github.com/Luismorlan/btc_in_go/layout"
 	"github.com/Luismorlan/btc_in_go/service"
+	"github.com/Luismorlan/btc_in_go/storage"
 	"github.com/Luismorlan/btc_in_go/visualize"
 	"github.com/jroimartin/gocui"
 	"google.golang.org/grpc"
@@ -28,6 +29,7 @@ var (
 	port       *string
 	configPath *string
 	keyPath    *string
+	dataDir    *string
 	debugMode  *bool
 	wan        *bool
 )
@@ -36,6 +38,7 @@ func init() {
 	port = flag.String("port", "10000", "port to listen to peers and wallet")
 	configPath = flag.String("config_path", "full_node/cmd/config.yaml", "path to full node config")
 	keyPath = flag.String("key_path", "/tmp/mykey.pem", "the path to read or write your credentials.")
+	dataDir = flag.String("data_dir", "", "directory to persist blocks, default to /tmp/btc_in_go/PORT. Use \"memory\" to disable persistence.")
 	debugMode = flag.Bool("debug_mode", false, "Using debug mode will disable fancy GUI.")
 	wan = flag.Bool("wan", false, "Expose this fullnode to WAN and connect with others remotely.")
 }
@@ -132,7 +135,8 @@ func HandleCommand(cmd chan commands.Command, server *full_node.FullNodeServer)
 				}
 			}()
 		case commands.KEY:
-			server.Log("\n===============DO NOT COPY THIS LINE================\n" + server.GetPublicKey() + "\n===============DO NOT COPY THIS LINE================")
+			server.Log("Address: " + server.GetRewardAddress())
+			server.Log("Public key: " + server.GetPublicKey())
 		case commands.INTRODUCE:
 			peers, err := server.Introduce(c.Args[0], c.Args[1])
 			if err != nil {
@@ -241,6 +245,22 @@ func localAddress() full_node.Address {
 	}
 }
 
+// Open the block store to persist the blockchain. Exit if it cannot be opened because
+// there's no need to continue without the blockchain.
+func OpenBlockStore(dir string) storage.BlockStore {
+	if dir == "memory" {
+		return storage.NewMemoryBlockStore()
+	}
+	if dir == "" {
+		dir = "/tmp/btc_in_go/" + *port
+	}
+	store, err := storage.OpenFileBlockStore(dir)
+	if err != nil {
+		log.Fatalln("fail to open block store: " + err.Error())
+	}
+	return store
+}
+
 // Return a gui handle if not in debug mode.
 func ListenOnInput(cmd chan commands.Command, debugMode bool) *gocui.Gui {
 	// Choose a fancy GUI
@@ -294,7 +314,9 @@ func main() {
 	g := ListenOnInput(cmd, *debugMode)
 
 	// Create a server with peer, config and a command channel to interrupt mining when tail changes.
-	server := full_node.NewFullNodeServer(cfg, []full_node.Peer{}, endpoint, *keyPath, cmd, g)
+	store := OpenBlockStore(*dataDir)
+	defer store.Close()
+	server := full_node.NewFullNodeServer(cfg, []full_node.Peer{}, endpoint, *keyPath, store, cmd, g)
 	service.RegisterFullNodeServiceServer(grpcServer, server)
 
 	go HandleCommand(cmd, server)
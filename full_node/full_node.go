@@ -1,17 +1,23 @@
 package full_node
 
 import (
+	"bytes"
 	"container/list"
-	"crypto/rsa"
 	"errors"
 	"fmt"
+	"log"
+	"math/big"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/Luismorlan/btc_in_go/commands"
 	"github.com/Luismorlan/btc_in_go/config"
 	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/storage"
 	"github.com/Luismorlan/btc_in_go/utils"
 	uuid "github.com/satori/go.uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // A full node should maintain the blockchain, and update the blockchain.
@@ -21,7 +27,7 @@ type FullNode struct {
 	// Transaction pool it need to maintain. Incoming transaction are added to this pool.
 	txPool *model.TransactionPool
 	// keys contain private key and public key for this fullnode. Although we mostly care about public key.
-	keys *rsa.PrivateKey
+	keys utils.PrivateKey
 	// Peers in the network.
 	// TODO(chenweilunster): Add this member.
 	// Blockchain config.
@@ -31,62 +37,227 @@ type FullNode struct {
 	// A unique indentifier of this Fullnode, this doesn't impact consensus, only
 	// used for easier implementation.
 	uuid string
+	// Where accepted blocks are persisted.
+	store storage.BlockStore
+	// Where to log chain events such as reorg, default to stdout if nil.
+	logger func(string)
+	// Blocks whose parent is not in the blockchain yet.
+	orphans *orphanPool
 }
 
-// Create a brand new full node, which contains a genesis block in the chain.
-func NewFullNode(c config.AppConfig, path string) *FullNode {
+// Create a full node on top of the given block store. The blockchain starts with the
+// genesis block, and all blocks already in the store are replayed in the order they
+// were accepted, which restores the parent/children links as well as the tail.
+func NewFullNode(c config.AppConfig, path string, store storage.BlockStore) *FullNode {
 	myuuid := uuid.NewV4()
-	sk := utils.ParseKeyFile(path, int(c.RSA_LEN))
-	return &FullNode{
-		blockchain: model.NewBlockChain(),
+	keyType, err := utils.ParseKeyType(c.KEY_TYPE)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	sk := utils.ParseKeyFile(path, keyType, int(c.RSA_LEN))
+	genesis := c.GENESIS_HASH
+	if genesis == "" {
+		genesis = model.GENESIS_HASH
+	}
+	if b, err := utils.HexToBytes(genesis); err != nil || len(b) > 32 {
+		log.Fatalln("genesis hash must be hex of up to 32 bytes: " + genesis)
+	}
+	f := &FullNode{
+		blockchain: model.NewBlockChainFromGenesis(genesis),
 		txPool:     model.NewTransactionPool(),
 		keys:       sk,
 		config:     c,
 		m:          sync.RWMutex{},
 		uuid:       myuuid.String(),
+		store:      store,
+		orphans:    newOrphanPool(c.MAX_ORPHAN_BLOCKS, c.MAX_ORPHAN_BYTES),
 	}
+
+	blocks, err := store.Blocks()
+	if err != nil {
+		log.Fatalln("fail to load blocks from storage: " + err.Error())
+	}
+	for _, b := range blocks {
+		_, _, err := f.handleNewBlock(b, false /*persist=*/)
+		if err != nil {
+			log.Println("fail to replay stored block: " + b.Hash + " err: " + err.Error())
+		}
+	}
+	return f
 }
 
-// Return public key in hex format.
+// Return public key encoded by utils.EncodePublicKey in hex format.
 func (f *FullNode) GetPublicKey() string {
-	return utils.BytesToHex(utils.PublicKeyToBytes(&f.keys.PublicKey))
+	return utils.BytesToHex(utils.EncodePublicKey(f.keys.Public()))
+}
+
+// Return the address mining rewards are paid to.
+func (f *FullNode) GetRewardAddress() string {
+	return utils.GetAddress(f.keys.Public())
 }
 
+// Validate the transaction and add it to pool. The transaction can spend outputs of
+// other pending transactions in the pool, and can replace conflicting pool transactions
+// opted in to replace-by-fee if it pays a higher fee.
+// If the pool is full, transactions paying the lowest fee rate are evicted, which could
+// be the new transaction itself.
 func (f *FullNode) AddTransactionToPool(tx *model.Transaction) error {
 	f.m.Lock()
 	defer f.m.Unlock()
 
-	if _, exist := f.txPool.TxPool[tx.Hash]; exist {
-		return fmt.Errorf("existing transaction, will not process: %s", tx.Hash)
+	now := time.Now().Unix()
+	utils.DecayPoolMinFeeRate(f.txPool, now)
+	replaced, err := utils.AddTransactionToPool(f.txPool, tx, f.blockchain.UTXOSet, f.getNextBlockContext(), now, f.getMinFeeRate())
+	if err != nil {
+		return err
+	}
+	if len(replaced) > 0 {
+		f.log(fmt.Sprintf("transaction %s replaced %d pool txs", tx.Hash, len(replaced)))
+	}
+	f.trimTxPool(now)
+	if _, exist := f.txPool.TxPool[tx.Hash]; !exist {
+		return fmt.Errorf("transaction pool is full, fee rate must be at least %d", f.getMinFeeRate())
 	}
-	f.txPool.TxPool[tx.Hash] = tx
 	return nil
 }
 
+// Return the context to validate transactions in a block on top of parent.
+func (f *FullNode) getValidationContext(parent *model.BlockWrapper) utils.ValidationContext {
+	return utils.ValidationContext{
+		Height:           parent.Height + 1,
+		MedianTimePast:   utils.GetMedianTimePast(parent),
+		CoinbaseMaturity: f.config.COINBASE_MATURITY,
+	}
+}
+
+// Return the context to validate transactions in the block after tail.
+func (f *FullNode) GetNextBlockContext() utils.ValidationContext {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	return f.getNextBlockContext()
+}
+
+// Return the context to validate transactions in the block after tail, the caller must
+// hold the lock.
+func (f *FullNode) getNextBlockContext() utils.ValidationContext {
+	return f.getValidationContext(f.blockchain.Tail)
+}
+
+// Return the minimum fee rate to enter the pool, the caller must hold the lock.
+func (f *FullNode) getMinFeeRate() int64 {
+	if f.txPool.MinFeeRate > f.config.MIN_RELAY_FEE_RATE {
+		return f.txPool.MinFeeRate
+	}
+	return f.config.MIN_RELAY_FEE_RATE
+}
+
+// Evict transactions if the pool is full, the caller must hold the lock.
+func (f *FullNode) trimTxPool(now int64) {
+	evicted := utils.TrimPool(f.txPool, f.config.MAX_POOL_BYTES, now)
+	if len(evicted) > 0 {
+		f.log(fmt.Sprintf("transaction pool is full: %d txs evicted, minimum fee rate raised to %d", len(evicted), f.txPool.MinFeeRate))
+	}
+}
+
+// Remove transactions staying in the pool for longer than MAX_POOL_TX_AGE, and decay the
+// pool minimum fee rate. This should be called periodically.
+func (f *FullNode) ExpireTransactions() {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	now := time.Now().Unix()
+	utils.DecayPoolMinFeeRate(f.txPool, now)
+	if f.config.MAX_POOL_TX_AGE <= 0 {
+		return
+	}
+	expired := utils.ExpirePool(f.txPool, now-f.config.MAX_POOL_TX_AGE)
+	if len(expired) > 0 {
+		f.log(fmt.Sprintf("%d txs expired from transaction pool", len(expired)))
+	}
+}
+
+// Estimate the fee rate for a new transaction from the last given number of blocks on the
+// main chain and the transaction pool, and return it with the pool minimum fee rate.
+func (f *FullNode) EstimateFeeRate(blocks int64) (int64, int64, error) {
+	f.m.RLock()
+	defer f.m.RUnlock()
+
+	if blocks <= 0 {
+		blocks = utils.FEE_ESTIMATE_BLOCKS
+	}
+	blockRates := []int64{}
+	for b := f.blockchain.Tail; b.Parent != nil && int64(len(blockRates)) < blocks; b = b.Parent {
+		rate, err := utils.GetBlockMinFeeRate(b.B, b.Undo, f.config.MAX_BLOCK_SIZE)
+		if err != nil {
+			return 0, 0, err
+		}
+		blockRates = append(blockRates, rate)
+	}
+	maxSize := f.config.MAX_BLOCK_SIZE
+	if maxSize > 0 {
+		maxSize -= utils.BLOCK_RESERVED_SIZE
+	}
+	minFeeRate := f.getMinFeeRate()
+	return utils.EstimateFeeRate(blockRates, utils.GetPoolMinFeeRate(f.txPool, maxSize), minFeeRate), minFeeRate, nil
+}
+
+// Coin supply at a block, amounts are in base units.
+type Supply struct {
+	Height int64
+	// Subsidy of the next block.
+	Subsidy int64
+	// Total value of all UTXOs, which is every coin issued so far.
+	Supply int64
+	// Total subsidy of all blocks so far, the most coins that can exist.
+	MaxSupply int64
+}
+
+// Return the coin supply at tail.
+func (f *FullNode) GetSupply() (Supply, error) {
+	f.m.RLock()
+	defer f.m.RUnlock()
+
+	height := f.blockchain.Tail.Height
+	supply, err := utils.GetLedgerValue(f.blockchain.UTXOSet)
+	if err != nil {
+		return Supply{}, err
+	}
+	return Supply{
+		Height:    height,
+		Subsidy:   utils.GetBlockSubsidy(height+1, f.config),
+		Supply:    supply,
+		MaxSupply: utils.GetTotalSubsidy(height, f.config),
+	}, nil
+}
+
+// Remove the transaction, together with all pending transactions depending on it.
 func (f *FullNode) RemoveTransactionFromPool(tx *model.Transaction) {
 	f.m.Lock()
 	defer f.m.Unlock()
 
-	delete(f.txPool.TxPool, tx.Hash)
+	utils.RemoveTransactionFromPool(f.txPool, tx.Hash)
 }
 
-// Return a deep copy of the ledger at given depth.
+// Return a deep copy of the ledger at given depth, which is rolled back from the UTXO
+// set at tail using undo records.
 func (f *FullNode) GetLedgerSnapshotAtDepth(depth int64) *model.Ledger {
 	f.m.RLock()
 	defer f.m.RUnlock()
 
+	l := utils.GetLedgerDeepCopy(f.blockchain.UTXOSet)
 	tail := f.blockchain.Tail
 	for i := 0; i < int(depth); i++ {
 		if tail.Parent == nil {
 			break
 		}
+		utils.RollbackBlock(l, tail.Undo)
 		tail = tail.Parent
 	}
-	l := utils.GetLedgerDeepCopy(tail.L)
 	return l
 }
 
-// Create a new block with all transactions in the provided transaction pool. CreateNewBlock
+// Create a new block with transactions in the provided transaction pool, the ones paying
+// the highest fee rate are selected first until the block is full. CreateNewBlock
 // is a really long process and takes a long time to proccess.
 // This block must be created after the tail block in the blockchain.
 // cmd is a channel that interrupts the mining process at any time
@@ -94,15 +265,26 @@ func (f *FullNode) CreateNewBlock(ctl chan commands.Command, height int64) (*mod
 	// Lock the transaction pool for reading.
 	f.m.RLock()
 	// Make a deepcopy of the ledger at tail.
-	l := utils.GetLedgerDeepCopy(f.blockchain.Tail.L)
+	l := utils.GetLedgerDeepCopy(f.blockchain.UTXOSet)
 	tail := f.blockchain.Tail
-	// TODO: Validate the transactions before actual mining.
-	txs := utils.GetAllTxsInPool(f.txPool)
+	maxSize := f.config.MAX_BLOCK_SIZE
+	if maxSize > 0 {
+		maxSize -= utils.BLOCK_RESERVED_SIZE
+	}
+	template := utils.SelectBlockTransactions(f.txPool, maxSize)
 	f.m.RUnlock()
+	f.log(fmt.Sprintf("block template at height %d: %d txs, %d bytes, total fee %s",
+		height, len(template.Txs), template.Size, utils.FormatAmount(template.Fee)))
 
 	// utils.CreateNewBlock is the actual mining, which is a really heavy task that could take
 	// minutes and takes a large amount of resources.
-	block, c, errTxs, err := utils.CreateNewBlock(txs, tail.B.Hash, f.config.COINBASE_REWARD, height, utils.PublicKeyToBytes(&f.keys.PublicKey), l, f.config.DIFFICULTY, ctl)
+	// Use local time as block time, unless it's not after median time past.
+	timestamp := time.Now().Unix()
+	if mtp := utils.GetMedianTimePast(tail); timestamp <= mtp {
+		timestamp = mtp + 1
+	}
+
+	block, c, errTxs, err := utils.CreateNewBlock(template.Txs, tail.B.Hash, utils.GetBlockSubsidy(height, f.config), f.getValidationContext(tail), f.keys.Public(), l, int(utils.GetNextDifficulty(tail, f.config)), timestamp, ctl)
 
 	// We need to clean up all failure transactions from the mining pool.
 	if len(errTxs) != 0 {
@@ -121,6 +303,58 @@ func (f *FullNode) GetHeight() int64 {
 	return f.blockchain.Tail.Height
 }
 
+// Return the block with the given hash on any branch of the blockchain.
+func (f *FullNode) GetBlock(hash string) (*model.Block, bool) {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	bw, exist := f.blockchain.Chain[hash]
+	if !exist {
+		return nil, false
+	}
+	return bw.B, true
+}
+
+// Whether the block with the given hash is in the blockchain or the orphan pool.
+func (f *FullNode) HasBlock(hash string) bool {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	_, exist := f.blockchain.Chain[hash]
+	return exist || f.orphans.has(hash)
+}
+
+// Return the transaction with the given hash from the transaction pool.
+func (f *FullNode) GetTransactionFromPool(hash string) (*model.Transaction, bool) {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	entry, exist := f.txPool.TxPool[hash]
+	if !exist {
+		return nil, false
+	}
+	return entry.Tx, true
+}
+
+// Keep a block whose parent is unknown in the orphan pool. Return the hash of the first
+// ancestor missing from both the blockchain and the orphan pool, which should be fetched
+// from peers, and whether the block is added.
+func (f *FullNode) AddOrphanBlock(b *model.Block) (string, bool) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if _, exist := f.blockchain.Chain[b.Hash]; exist {
+		return "", false
+	}
+	if !f.orphans.add(b) {
+		return "", false
+	}
+	return f.orphans.getMissingAncestor(b.Hash), true
+}
+
+// Remove and return orphan blocks whose parent is the given block.
+func (f *FullNode) TakeOrphanChildren(hash string) []*model.Block {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.orphans.takeChildren(hash)
+}
+
 // Return tail of the blockchain.
 func (f *FullNode) GetTail() *model.BlockWrapper {
 	f.m.RLock()
@@ -131,17 +365,82 @@ func (f *FullNode) GetTail() *model.BlockWrapper {
 // Create a snapshot of the given public key's ledger, return all UTXO it has.
 // The snapshot must be obtained at the CONFIRMATION blocks ago, instead of directly
 // snapshot at the tail. See bitcoin whitepaper for more details on block confirmation.
+// pk is encoded by utils.EncodePublicKey.
 func (f *FullNode) GetUtxoForPublicKey(pk []byte) model.Ledger {
+	return f.getUtxos(func(output *model.Output) bool {
+		return utils.IsPaidTo(output, pk)
+	})
+}
+
+// Like GetUtxoForPublicKey, but return all UTXO locked by the locking script.
+func (f *FullNode) GetUtxoForScript(locking []byte) model.Ledger {
+	return f.getUtxos(func(output *model.Output) bool {
+		return bytes.Equal(utils.GetLockingScript(output), locking)
+	})
+}
+
+// Return all UTXO whose output matches, confirmed by CONFIRMATION blocks.
+func (f *FullNode) getUtxos(match func(output *model.Output) bool) model.Ledger {
 	l := f.GetLedgerSnapshotAtDepth(f.config.CONFIRMATION)
 	res := model.NewLedger()
 	for utxoLite, output := range l.L {
-		if utils.IsSameBytes(pk, output.PublicKey) {
+		if match(output) {
 			res.L[utxoLite] = output
+			if meta, exist := l.Meta[utxoLite]; exist {
+				res.Meta[utxoLite] = meta
+			}
 		}
 	}
 	return *res
 }
 
+// Return transactions spending outputs locked by the locking script, on the main chain
+// from the oldest, followed by pool transactions ordered by hash.
+func (f *FullNode) GetScriptSpends(locking []byte) []*model.Transaction {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	blocks := []*model.Block{}
+	for bw := f.blockchain.Tail; bw.Parent != nil; bw = bw.Parent {
+		blocks = append([]*model.Block{bw.B}, blocks...)
+	}
+	// Outputs locked by the script seen so far.
+	locked := make(map[model.UTXOLite]bool)
+	addLocked := func(tx *model.Transaction) {
+		for i, output := range tx.Outputs {
+			if bytes.Equal(utils.GetLockingScript(output), locking) {
+				locked[model.UTXOLite{PrevTxHash: tx.Hash, Index: int64(i)}] = true
+			}
+		}
+	}
+	res := []*model.Transaction{}
+	addSpends := func(tx *model.Transaction) {
+		for _, input := range tx.Inputs {
+			if locked[model.UTXOLite{PrevTxHash: input.PrevTxHash, Index: input.Index}] {
+				res = append(res, tx)
+				return
+			}
+		}
+	}
+	for _, b := range blocks {
+		for _, tx := range b.Txs {
+			addSpends(tx)
+			addLocked(tx)
+		}
+		addLocked(b.Coinbase)
+	}
+
+	hashes := []string{}
+	for hash, entry := range f.txPool.TxPool {
+		hashes = append(hashes, hash)
+		addLocked(entry.Tx)
+	}
+	sort.Strings(hashes)
+	for _, hash := range hashes {
+		addSpends(f.txPool.TxPool[hash].Tx)
+	}
+	return res
+}
+
 // Handle the new block received.
 // This function should:
 // 1. Validate the block.
@@ -164,27 +463,46 @@ func (f *FullNode) HandleNewBlock(pendingBlock *model.Block) (bool, bool, error)
 	f.m.Lock()
 	defer f.m.Unlock()
 
+	return f.handleNewBlock(pendingBlock, true /*persist=*/)
+}
+
+// Validate and add the block to blockchain, the caller must hold the lock. The block is
+// written to the block store before it is linked into the blockchain if persist is set,
+// otherwise it is assumed to be already stored (e.g. when replaying on startup).
+func (f *FullNode) handleNewBlock(pendingBlock *model.Block, persist bool) (bool, bool, error) {
 	// Block should not already exist in blockchain.
 	if _, ok := f.blockchain.Chain[pendingBlock.Hash]; ok {
 		return false, false, fmt.Errorf("block already exist in the chain: %s", pendingBlock.Hash)
 	}
 
 	tailChange := false
-	// Difficulty and hash should match.
-	match, _ := utils.MatchDifficulty(pendingBlock, f.config.DIFFICULTY)
-	if !match {
-		return tailChange, false, errors.New("match difficulty failed for block: " + pendingBlock.Hash)
+	if pendingBlock.Coinbase == nil {
+		return tailChange, false, errors.New("block is missing coinbase: " + pendingBlock.Hash)
+	}
+	if size := proto.Size(pendingBlock); f.config.MAX_BLOCK_SIZE > 0 && int64(size) > f.config.MAX_BLOCK_SIZE {
+		return tailChange, false, fmt.Errorf("block size %d exceeds maximum %d", size, f.config.MAX_BLOCK_SIZE)
+	}
+	err := utils.CheckBlockHeader(pendingBlock.Hash, pendingBlock.Header, time.Now().Unix())
+	if err != nil {
+		return tailChange, false, err
 	}
-	blockBytes, err := utils.GetBlockBytes(pendingBlock)
+	header := pendingBlock.Header
+
+	// Merkle roots in header should commit to exactly the transactions in the body, and
+	// their signatures.
+	merkleRoot, witnessRoot, err := utils.ComputeBlockRoots(pendingBlock)
 	if err != nil {
 		return tailChange, false, err
 	}
-	if utils.BytesToHex(utils.SHA256(blockBytes)) != pendingBlock.Hash {
-		return tailChange, false, errors.New("block hash is invalid")
+	if merkleRoot != header.MerkleRoot {
+		return tailChange, false, errors.New("merkle root doesn't match block transactions: " + pendingBlock.Hash)
+	}
+	if witnessRoot != header.WitnessRoot {
+		return tailChange, false, errors.New("witness root doesn't match block signatures: " + pendingBlock.Hash)
 	}
 
 	// previous block should exist in blockchain.
-	prevHash := pendingBlock.PrevHash
+	prevHash := header.PrevHash
 	prevBlockWrapper, ok := f.blockchain.Chain[prevHash]
 	if !ok {
 		// Parent not found in blockchain could signal that we're out of sync.
@@ -199,8 +517,29 @@ func (f *FullNode) HandleNewBlock(pendingBlock *model.Block) (bool, bool, error)
 		return tailChange, false, errors.New("parent is buried too deep")
 	}
 
-	// Here we need to make a deep copy of the entire previous block's ledger because we are chaning it.
-	l := utils.GetLedgerDeepCopy(prevBlockWrapper.L)
+	// Difficulty and timestamp must follow the parent chain.
+	err = utils.CheckBlockHeaderContext(header, prevBlockWrapper, f.config)
+	if err != nil {
+		return tailChange, false, err
+	}
+
+	// Validate the block on top of its parent's ledger. When extending the tail, which is the
+	// most common case, the UTXO set is changed in place and rolled back if the block is invalid.
+	// Otherwise parent's ledger is derived from the UTXO set by rolling back and forward.
+	extendTail := prevBlockWrapper == f.blockchain.Tail
+	var l *model.Ledger
+	if extendTail {
+		l = f.blockchain.UTXOSet
+	} else {
+		l = utils.GetLedgerAtBlock(f.blockchain, prevBlockWrapper)
+	}
+	undo := &model.BlockUndo{}
+	rollback := func() {
+		if extendTail {
+			utils.RollbackBlock(l, undo)
+		}
+	}
+
 	// Total transaction fee.
 	fee, err := utils.CalcTxFee(pendingBlock.Txs, l)
 	if err != nil {
@@ -208,43 +547,202 @@ func (f *FullNode) HandleNewBlock(pendingBlock *model.Block) (bool, bool, error)
 	}
 
 	// Coinbase should be valid.
-	err = utils.IsValidCoinbase(pendingBlock.Coinbase, fee+f.config.COINBASE_REWARD)
+	height := prevBlockWrapper.Height + 1
+	err = utils.IsValidCoinbase(pendingBlock.Coinbase, fee, height, f.config)
 	if err != nil {
 		return tailChange, false, err
 	}
 
 	// Handle all non-coinbase transactions and process Coinbase.
-	_, err = utils.HandleTransactions(pendingBlock.Txs, l)
+	ctx := f.getValidationContext(prevBlockWrapper)
+	_, err = utils.HandleTransactions(pendingBlock.Txs, l, undo, ctx)
 	if err != nil {
+		rollback()
 		return tailChange, false, err
 	}
-	utils.ProcessInputsAndOutputs(pendingBlock.Coinbase, l)
+	utils.ProcessInputsAndOutputs(pendingBlock.Coinbase, l, undo, ctx)
+
+	// The block is valid, persist it before making it visible.
+	if persist {
+		if err := f.store.Put(pendingBlock, height); err != nil {
+			rollback()
+			return tailChange, false, fmt.Errorf("fail to persist block %s: %s", pendingBlock.Hash, err.Error())
+		}
+	}
 
 	// Add block to blockchain and remove all transaction from the Tx pool.
 	blockWrapper := model.BlockWrapper{
-		B:      pendingBlock,
-		Parent: prevBlockWrapper,
-		Height: prevBlockWrapper.Height + 1,
-		L:      l,
+		B:         pendingBlock,
+		Parent:    prevBlockWrapper,
+		Height:    height,
+		Undo:      undo,
+		TotalWork: new(big.Int).Add(prevBlockWrapper.TotalWork, utils.GetBlockWork(header.Bits)),
 	}
 
 	// Add the new block to the children of parent block.
 	prevBlockWrapper.Children = append(prevBlockWrapper.Children, &blockWrapper)
 
 	f.blockchain.Chain[pendingBlock.Hash] = &blockWrapper
-	if blockWrapper.Height > f.blockchain.Tail.Height {
+	if utils.IsBetterTip(&blockWrapper, f.blockchain.Tail) {
+		// When switching to another branch, ledger derived above is exactly the UTXO set
+		// at the new tail.
+		oldTail := f.blockchain.Tail
+		f.blockchain.UTXOSet = l
 		f.blockchain.Tail = &blockWrapper
 		tailChange = true
-	}
-	for i := 0; i < len(pendingBlock.Txs); i++ {
-		tx := pendingBlock.Txs[i]
-		delete(f.txPool.TxPool, tx.Hash)
+		f.reconcileTxPool(oldTail, &blockWrapper)
 	}
 
 	return tailChange, false, nil
 }
 
+// Bring the transaction pool in line with the new tail, the caller must hold the lock.
+// 1. Transactions confirmed by blocks connected to the main chain are removed.
+// 2. Transactions confirmed only in blocks disconnected from the main chain go back to the pool.
+// 3. Transactions no longer valid on the new UTXO set are evicted, e.g. double spending
+//    with a transaction in the new branch, together with their descendants.
+// The pool is rebuilt by adding back transactions from disconnected blocks, oldest first,
+// then the pool transactions in topological order, so dependency edges stay consistent.
+// If the tail moved to another branch, a reorg event is logged.
+func (f *FullNode) reconcileTxPool(oldTail *model.BlockWrapper, newTail *model.BlockWrapper) {
+	fork := utils.GetForkPoint(oldTail, newTail)
+
+	confirmed := make(map[string]bool)
+	for b := newTail; b != fork; b = b.Parent {
+		for _, tx := range b.B.Txs {
+			confirmed[tx.Hash] = true
+		}
+	}
+
+	depth := 0
+	disconnected := [][]*model.Transaction{}
+	for b := oldTail; b != fork; b = b.Parent {
+		depth++
+		disconnected = append(disconnected, b.B.Txs)
+	}
+	txs := []*model.Transaction{}
+	returned := 0
+	for i := len(disconnected) - 1; i >= 0; i-- {
+		for _, tx := range disconnected[i] {
+			if !confirmed[tx.Hash] {
+				txs = append(txs, tx)
+				returned++
+			}
+		}
+	}
+	txs = append(txs, utils.GetAllTxsInPool(f.txPool)...)
+
+	// Transactions already in the pool keep their time, and those already accepted are
+	// not subject to the minimum fee rate again, only to the pool size limit.
+	now := time.Now().Unix()
+	oldPool := f.txPool
+	f.txPool = model.NewTransactionPool()
+	f.txPool.MinFeeRate = oldPool.MinFeeRate
+	f.txPool.MinFeeRateTime = oldPool.MinFeeRateTime
+	evicted := 0
+	for _, tx := range txs {
+		if confirmed[tx.Hash] {
+			continue
+		}
+		t := now
+		if entry, exist := oldPool.TxPool[tx.Hash]; exist {
+			t = entry.Time
+		}
+		if _, err := utils.AddTransactionToPool(f.txPool, tx, f.blockchain.UTXOSet, f.getNextBlockContext(), t, 0 /*minFeeRate=*/); err != nil {
+			evicted++
+		}
+	}
+	evicted += len(utils.TrimPool(f.txPool, f.config.MAX_POOL_BYTES, now))
+
+	if depth > 0 {
+		f.log(fmt.Sprintf("reorg: depth %d, old tip %s (height %d), new tip %s (height %d), fork point %s (height %d), %d txs returned to pool, %d txs evicted",
+			depth, oldTail.B.Hash, oldTail.Height, newTail.B.Hash, newTail.Height, fork.B.Hash, fork.Height, returned, evicted))
+	}
+}
+
+// Log the message with the logger provided by the server, or to stdout if there is none.
+func (f *FullNode) log(s string) {
+	if f.logger == nil {
+		log.Println(s)
+		return
+	}
+	f.logger(s)
+}
+
+// Maximum number of headers returned by a single GetHeaders.
+const MAX_HEADERS_PER_REQUEST = 2000
+
+// Maximum number of blocks returned by a single GetBlocksByHash.
+const MAX_BLOCKS_PER_REQUEST = 128
+
+// Return the wrapper of the block with the given hash on any branch. Parent, height and
+// total work of a wrapper never change once it's in the blockchain.
+func (f *FullNode) GetBlockWrapper(hash string) (*model.BlockWrapper, bool) {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	bw, exist := f.blockchain.Chain[hash]
+	return bw, exist
+}
+
+// Return headers on the main chain right after the first locator hash found on the main
+// chain, or right after genesis if none is found. At most number headers are returned,
+// and it stops after the block with stopHash.
+func (f *FullNode) GetHeaders(locator []string, stopHash string, number int) []*model.BlockHeader {
+	if number <= 0 || number > MAX_HEADERS_PER_REQUEST {
+		number = MAX_HEADERS_PER_REQUEST
+	}
+	f.m.RLock()
+	defer f.m.RUnlock()
+
+	tail := f.blockchain.Tail
+	var start int64 = 0
+	for _, hash := range locator {
+		bw, exist := f.blockchain.Chain[hash]
+		if exist && utils.GetAncestor(tail, bw.Height) == bw {
+			start = bw.Height
+			break
+		}
+	}
+
+	end := start + int64(number)
+	if end > tail.Height {
+		end = tail.Height
+	}
+	blocks := []*model.Block{}
+	for bw := utils.GetAncestor(tail, end); bw != nil && bw.Height > start; bw = bw.Parent {
+		blocks = append(blocks, bw.B)
+	}
+	headers := []*model.BlockHeader{}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		headers = append(headers, blocks[i].Header)
+		if blocks[i].Hash == stopHash {
+			break
+		}
+	}
+	return headers
+}
+
+// Return blocks with the given hashes from any branch, in the given order. Unknown hashes
+// are skipped, and at most MAX_BLOCKS_PER_REQUEST blocks are returned.
+func (f *FullNode) GetBlocksByHash(hashes []string) []*model.Block {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	blocks := []*model.Block{}
+	for _, hash := range hashes {
+		if len(blocks) >= MAX_BLOCKS_PER_REQUEST {
+			break
+		}
+		if bw, exist := f.blockchain.Chain[hash]; exist {
+			blocks = append(blocks, bw.B)
+		}
+	}
+	return blocks
+}
+
 // GetBlocks returns a $number of blocks starting from the given hash. It only returns blocks from the longest chain.
+//
+// Deprecated: it returns blocks from genesis if the hash is not on the longest chain, use
+// GetHeaders and GetBlocksByHash instead.
 func (f *FullNode) GetBlocks(hash string, number int) ([]*model.Block, bool) {
 	f.m.RLock()
 	dq := list.New()
@@ -12,6 +12,7 @@ import (
 	"github.com/Luismorlan/btc_in_go/config"
 	"github.com/Luismorlan/btc_in_go/model"
 	"github.com/Luismorlan/btc_in_go/service"
+	"github.com/Luismorlan/btc_in_go/storage"
 	"github.com/Luismorlan/btc_in_go/utils"
 	"github.com/Luismorlan/btc_in_go/visualize"
 	"github.com/jroimartin/gocui"
@@ -29,6 +30,8 @@ type Peer struct {
 	addr Address
 	// The connection for this peer. Each peer/client has a dedicated connection.
 	conn *grpc.ClientConn
+	// Inventory known by the peer and announcements waiting to be sent to it.
+	relay *peerRelay
 }
 
 // Stringer function of peer.
@@ -63,6 +66,11 @@ type FullNodeServer struct {
 	blockFailure int
 	syncing      bool
 
+	// Hashes of blocks and transactions being requested from peers, so that an object
+	// announced by multiple peers is only downloaded once.
+	requested map[string]bool
+	reqM      sync.Mutex
+
 	// A command channel to pass command to other part of the system.
 	// For now, the only use is the interrupt mining process on tail change.
 	cmd chan commands.Command
@@ -80,6 +88,11 @@ func (sev *FullNodeServer) GetPublicKey() string {
 	return sev.fullNode.GetPublicKey()
 }
 
+// Get the address mining rewards are paid to.
+func (sev *FullNodeServer) GetRewardAddress() string {
+	return sev.fullNode.GetRewardAddress()
+}
+
 // Return all current peers.
 func (sev *FullNodeServer) GetAllPeers() []Peer {
 	sev.m.RLock()
@@ -96,57 +109,73 @@ func (sev *FullNodeServer) GetPeer(idx int) (Peer, error) {
 	return sev.peers[idx], nil
 }
 
-// Set transaction should add transaction to pool and broad cast to peer.
+// Set transaction should add transaction to pool and announce to peers.
 func (sev *FullNodeServer) SetTransaction(con context.Context, req *service.SetTransactionRequest) (*service.SetTransactionResponse, error) {
 	tx := req.GetTx()
 	if tx == nil {
 		return &service.SetTransactionResponse{}, nil
 	}
+	return &service.SetTransactionResponse{}, sev.HandleTransaction(tx)
+}
 
-	// First validate the transaction. This is totally optional but is a nice to have optimization.
-	l := sev.fullNode.GetLedgerSnapshotAtDepth(0)
-	err := utils.IsValidTransaction(tx, l)
-	if err != nil {
-		sev.Log("invalid incoming transaction: " + err.Error())
-		return &service.SetTransactionResponse{}, nil
-	}
-
-	// Add the transaction to pool.
-	err = sev.fullNode.AddTransactionToPool(tx)
+// Validate the transaction, add it to pool and announce it to peers.
+func (sev *FullNodeServer) HandleTransaction(tx *model.Transaction) error {
+	// Validate and add the transaction to pool, it can spend outputs of pending transactions.
+	err := sev.fullNode.AddTransactionToPool(tx)
 	if err != nil {
 		sev.Log("fail to add transaction to pool: " + err.Error())
-		return &service.SetTransactionResponse{}, err
-	}
-
-	// Broadcast to all other nodes.
-	sev.m.RLock()
-	defer sev.m.RUnlock()
-	for i := 0; i < len(sev.peers); i++ {
-		peer := sev.peers[i]
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		peer.client.SetTransaction(ctx, &service.SetTransactionRequest{Tx: tx})
+		return err
 	}
 
-	return &service.SetTransactionResponse{}, nil
+	sev.AnnounceInventory(&service.InvItem{Type: service.InvType_INV_TX, Hash: tx.Hash})
+	return nil
 }
 
-// Return all utxo the public key owned.
+// Return all utxo the public key owned, or locked by the script if given.
 func (sev *FullNodeServer) GetBalance(ctx context.Context, req *service.GetBalanceRequest) (*service.GetBalanceResponse, error) {
-	pk := req.PublicKey
-	l := sev.fullNode.GetUtxoForPublicKey(pk)
+	var l model.Ledger
+	if len(req.Script) > 0 {
+		l = sev.fullNode.GetUtxoForScript(req.Script)
+	} else {
+		l = sev.fullNode.GetUtxoForPublicKey(req.PublicKey)
+	}
+	next := sev.fullNode.GetNextBlockContext()
 	res := service.GetBalanceResponse{}
 	for utxoLite, output := range l.L {
 		utxo := model.GetUtxo(&utxoLite)
 		pair := service.UtxoOutputPair{
-			Utxo:   &utxo,
-			Output: output,
+			Utxo:     &utxo,
+			Output:   output,
+			Immature: !utils.IsMature(&l, utxoLite, next),
 		}
 		res.UtxoOutputPairs = append(res.UtxoOutputPairs, &pair)
 	}
 	return &res, nil
 }
 
+// Estimate the fee rate a new transaction should pay.
+func (sev *FullNodeServer) EstimateFee(ctx context.Context, req *service.EstimateFeeRequest) (*service.EstimateFeeResponse, error) {
+	feeRate, minFeeRate, err := sev.fullNode.EstimateFeeRate(req.Blocks)
+	if err != nil {
+		return nil, err
+	}
+	return &service.EstimateFeeResponse{FeeRate: feeRate, MinFeeRate: minFeeRate}, nil
+}
+
+// Report the block subsidy and the total supply.
+func (sev *FullNodeServer) GetSupply(ctx context.Context, req *service.GetSupplyRequest) (*service.GetSupplyResponse, error) {
+	s, err := sev.fullNode.GetSupply()
+	if err != nil {
+		return nil, err
+	}
+	return &service.GetSupplyResponse{Height: s.Height, Subsidy: s.Subsidy, Supply: s.Supply, MaxSupply: s.MaxSupply}, nil
+}
+
+// Return transactions spending outputs locked by the script in request.
+func (sev *FullNodeServer) GetScriptSpends(ctx context.Context, req *service.GetScriptSpendsRequest) (*service.GetScriptSpendsResponse, error) {
+	return &service.GetScriptSpendsResponse{Txs: sev.fullNode.GetScriptSpends(req.Script)}, nil
+}
+
 // Mine one block and set that block.
 func (sev *FullNodeServer) Mine(ctl chan commands.Command) (commands.Command, error) {
 	// We are mining a block at a new height.
@@ -166,58 +195,200 @@ func (sev *FullNodeServer) Mine(ctl chan commands.Command) (commands.Command, er
 	return commands.NewDefaultCommand(), err
 }
 
-// Round-robin query peers to sync to the latest block.
+// Number of blocks requested from a peer at once during sync.
+const BLOCKS_PER_BATCH = 16
+
+// Sync to the best chain among peers with headers-first synchronization. Headers are
+// downloaded and validated from every peer first, then bodies of the best header chain
+// are downloaded in parallel from all peers and added to the blockchain in order.
 func (sev *FullNodeServer) SyncToLatest() error {
 	sev.Log("start syncing...")
 	// boolean flag doesn't need mutex protection because it's a benign race.
 	sev.syncing = true
+	defer func() {
+		sev.m.Lock()
+		defer sev.m.Unlock()
+		// Although each of them is benign race, changing them together is not,
+		// thus we need to use a mutex to protect them.
+		sev.syncing = false
+		sev.blockFailure = 0
+	}()
 
-	peerSize := len(sev.GetAllPeers())
-	if peerSize == 0 {
+	peers := sev.GetReadyPeers()
+	if len(peers) == 0 {
 		return errors.New("no peer to sync")
 	}
-	// TODO(chenweilunster): Make this configurable
-	batch_size := 5
-	tail := sev.fullNode.GetTail()
-	i := 0
-	for {
-		// Peer size might change, we must get peer size every time.
-		peerSize = len(sev.GetAllPeers())
-		i = i % peerSize
-		p, err := sev.GetPeer(i)
-		if err != nil || p.conn.GetState() != connectivity.Ready {
-			// Don't return error here. The peer might be just deleted or some other reason.
-			continue
+
+	headers := newHeaderChain(sev.fullNode)
+	for _, p := range peers {
+		err := sev.SyncHeaders(p, headers)
+		if err != nil {
+			// Other peers might still give us the best chain.
+			sev.Log("fail to sync headers from " + p.String() + ": " + err.Error())
 		}
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		res, err := p.client.Sync(ctx, &service.SyncRequest{Hash: tail.B.Hash, Number: int64(batch_size)})
+	}
+	hashes := headers.getMissingBlocks()
+	if len(hashes) == 0 {
+		sev.Log("fully synced")
+		return nil
+	}
+	sev.Log(fmt.Sprintf("synced headers to height %d, downloading %d blocks", headers.best.Height, len(hashes)))
+
+	err := sev.SyncBlocks(peers, hashes)
+	if err != nil {
+		return err
+	}
+	sev.Log("fully synced")
+	return nil
+}
+
+// Return all peers whose connection is ready.
+func (sev *FullNodeServer) GetReadyPeers() []Peer {
+	sev.m.RLock()
+	defer sev.m.RUnlock()
+	peers := []Peer{}
+	for i := 0; i < len(sev.peers); i++ {
+		if sev.peers[i].conn.GetState() == connectivity.Ready {
+			peers = append(peers, sev.peers[i])
+		}
+	}
+	return peers
+}
+
+// Download headers of the peer's main chain into the header chain, starting from where
+// the peer's main chain forks from our tail.
+func (sev *FullNodeServer) SyncHeaders(p Peer, headers *headerChain) error {
+	tip := sev.fullNode.GetTail()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		res, err := p.client.GetHeaders(ctx, &service.GetHeadersRequest{
+			Locator: utils.GetBlockLocator(tip),
+			Number:  MAX_HEADERS_PER_REQUEST,
+		})
+		cancel()
 		if err != nil {
 			return err
 		}
-		// Add blocks to blockchain.
-		for i := 0; i < len(res.Block); i++ {
-			b := res.Block[i]
-			sev.SetBlockInternal(&service.SetBlockRequest{Block: b}, false /*broadcast=*/)
+
+		now := time.Now().Unix()
+		for _, header := range res.Headers {
+			tip, err = headers.add(header, now)
+			if err != nil {
+				return err
+			}
 		}
-		if res.Synced {
-			sev.Log("fully synced")
-			break
+		sev.Log(fmt.Sprintf("received %d headers from %s, height %d", len(res.Headers), p, tip.Height))
+		if len(res.Headers) < MAX_HEADERS_PER_REQUEST {
+			return nil
 		}
-		// Update tail and choose the next peer.
-		tail = sev.fullNode.GetTail()
-		i++
 	}
+}
 
-	sev.m.Lock()
-	defer sev.m.Unlock()
-	// Although each of them is benign race, changing them together is not,
-	// thus we need to use a mutex to protect them.
-	sev.syncing = false
-	sev.blockFailure = 0
+// A batch of blocks to download during sync, start is the index of the first block.
+type blockBatch struct {
+	start  int
+	hashes []string
+}
+
+type blockBatchResult struct {
+	batch  blockBatch
+	blocks []*model.Block
+	peer   Peer
+	err    error
+}
+
+// Download the blocks in batches from all peers in parallel, and add them to the
+// blockchain in the given order as soon as they arrive. A peer stops downloading once it
+// fails, and its batch is retried by other peers.
+func (sev *FullNodeServer) SyncBlocks(peers []Peer, hashes []string) error {
+	numBatches := (len(hashes) + BLOCKS_PER_BATCH - 1) / BLOCKS_PER_BATCH
+	pending := make(chan blockBatch, numBatches)
+	results := make(chan blockBatchResult)
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < len(hashes); i += BLOCKS_PER_BATCH {
+		end := i + BLOCKS_PER_BATCH
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		pending <- blockBatch{start: i, hashes: hashes[i:end]}
+	}
+
+	for _, p := range peers {
+		go func(p Peer) {
+			for {
+				select {
+				case <-done:
+					return
+				case batch := <-pending:
+					blocks, err := sev.FetchBlocks(p, batch.hashes)
+					select {
+					case results <- blockBatchResult{batch: batch, blocks: blocks, peer: p, err: err}:
+					case <-done:
+						return
+					}
+					if err != nil {
+						return
+					}
+				}
+			}
+		}(p)
+	}
+
+	blocks := make([]*model.Block, len(hashes))
+	downloaded, connected := 0, 0
+	alive := len(peers)
+	anyTailChange := false
+	defer func() { sev.NotifyTailChange(anyTailChange) }()
+	for downloaded < len(hashes) {
+		r := <-results
+		if r.err != nil {
+			sev.Log("fail to download blocks from " + r.peer.String() + ": " + r.err.Error())
+			alive--
+			if alive == 0 {
+				return errors.New("no peer left to download blocks from")
+			}
+			pending <- r.batch
+			continue
+		}
+		copy(blocks[r.batch.start:], r.blocks)
+		downloaded += len(r.blocks)
+
+		for connected < len(blocks) && blocks[connected] != nil {
+			b := blocks[connected]
+			_, tailChange, _, err := sev.SetBlockInternal(&service.SetBlockRequest{Block: b}, false /*broadcast=*/)
+			// The block might have been relayed to us in the meantime.
+			if _, exist := sev.fullNode.GetBlockWrapper(b.Hash); err != nil && !exist {
+				return err
+			}
+			anyTailChange = anyTailChange || tailChange
+			connected++
+		}
+		sev.Log(fmt.Sprintf("downloaded %d/%d blocks, height %d", downloaded, len(hashes), sev.fullNode.GetHeight()))
+	}
 	return nil
 }
 
+// Download blocks with the given hashes from the peer, the peer must return all of them
+// in the given order.
+func (sev *FullNodeServer) FetchBlocks(p Peer, hashes []string) ([]*model.Block, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err := p.client.GetBlocksByHash(ctx, &service.GetBlocksByHashRequest{Hashes: hashes})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Blocks) != len(hashes) {
+		return nil, fmt.Errorf("expect %d blocks but got %d", len(hashes), len(res.Blocks))
+	}
+	for i := 0; i < len(hashes); i++ {
+		if res.Blocks[i].GetHash() != hashes[i] {
+			return nil, errors.New("unexpected block: " + res.Blocks[i].GetHash())
+		}
+	}
+	return res.Blocks, nil
+}
+
 // Add a connection to peer, note that this is a best effort 2-way connection.
 func (sev *FullNodeServer) AddPeer(ctx context.Context, req *service.AddPeerRequest) (*service.AddPeerResponse, error) {
 	_, err := sev.AddPeerInternal(req)
@@ -231,6 +402,7 @@ func (sev *FullNodeServer) AddPeerInternal(req *service.AddPeerRequest) (service
 	sev.m.RLock()
 	for _, p := range sev.peers {
 		if p.addr.IpAddr == req.NodeAddr.IpAddr && p.addr.Port == req.NodeAddr.Port {
+			sev.m.RUnlock()
 			return nil, errors.New(PEER_ALREADY_EXIST_ERR)
 		}
 	}
@@ -255,13 +427,16 @@ func (sev *FullNodeServer) AddPeerInternal(req *service.AddPeerRequest) (service
 		Port:   nodeAddr.Port,
 	}
 
-	sev.m.Lock()
-	sev.peers = append(sev.peers, Peer{
+	peer := Peer{
 		client: client,
 		addr:   addr,
 		conn:   conn,
-	})
+		relay:  newPeerRelay(),
+	}
+	sev.m.Lock()
+	sev.peers = append(sev.peers, peer)
 	sev.m.Unlock()
+	sev.StartRelay(peer)
 
 	// Spin up a process that GC idle connection, we GC the client in a
 	// expo backoff way to avoid overloading any peer.
@@ -303,6 +478,7 @@ func (sev *FullNodeServer) RemovePeer(addr Address) {
 	for i := 0; i < len(sev.peers); i++ {
 		if sev.peers[i].addr == addr {
 			// Find the peer in peer list and remove it.
+			sev.peers[i].relay.stop()
 			sev.peers = append(sev.peers[:i], sev.peers[i+1:]...)
 			return
 		}
@@ -326,6 +502,7 @@ func (sev *FullNodeServer) AddMutualConnection(ipAddr string, port string) error
 	if err != nil && err.Error() != PEER_ALREADY_EXIST_ERR {
 		// Peer cannot add a new peer, prune this peer.
 		sev.Log(err.Error())
+		sev.peers[len(sev.peers)-1].relay.stop()
 		sev.peers = sev.peers[:len(sev.peers)-1]
 		return err
 	}
@@ -333,36 +510,131 @@ func (sev *FullNodeServer) AddMutualConnection(ipAddr string, port string) error
 }
 
 // Handle the incoming block, this is the external RPC not intended to be called by
-// internal functions. If the block is valid, just broadcast it to other nodes.
+// internal functions. If the block is valid, just announce it to other nodes.
 func (sev *FullNodeServer) SetBlock(con context.Context, req *service.SetBlockRequest) (*service.SetBlockResponse, error) {
 	sev.Log(fmt.Sprintf("received a new block: %s", req.Block.Hash))
-	res, tailChange, outOfSync, err := sev.SetBlockInternal(req, true /*broadcast=*/)
-	// If there is a possible signal of out of sync, and we are not currently syncing,
-	// we should try to sync with peer in a round robin manner.
-	if err != nil && outOfSync && !sev.syncing {
-		sev.m.Lock()
-		sev.blockFailure++
-		if sev.blockFailure >= int(sev.fullNode.config.CONFIRMATION) {
-			sev.cmd <- commands.Command{
-				Op: commands.SYNC,
-			}
+	if req.Sender != nil {
+		// No need to announce the block back to the sender.
+		if peer, err := sev.GetPeerByAddress(Address{IpAddr: req.Sender.IpAddr, Port: req.Sender.Port}); err == nil {
+			peer.relay.markKnown(req.Block.Hash)
 		}
-		sev.m.Unlock()
+	}
+	sev.HandleBlock(req.Block, req.Sender)
+	return &service.SetBlockResponse{}, nil
+}
+
+// Handle the block received from sender, which is nil if the block doesn't come from a
+// full node. If the block is valid, announce it to other nodes.
+func (sev *FullNodeServer) HandleBlock(b *model.Block, sender *service.NodeAddr) {
+	_, tailChange, outOfSync, err := sev.SetBlockInternal(&service.SetBlockRequest{Block: b, Sender: sender}, true /*broadcast=*/)
+	// Parent of the block is unknown, keep it as orphan and fetch its ancestors.
+	if err != nil && outOfSync {
+		sev.HandleOrphanBlock(b, sender)
 	}
 
-	// Only external block handling incurred tail change interrupts the mining process.
+	sev.NotifyTailChange(tailChange)
+	if err != nil {
+		sev.Log("fail to handle incoming block: " + b.Hash + " err: " + err.Error())
+	}
+}
+
+// Only external block handling incurred tail change interrupts the mining process.
+func (sev *FullNodeServer) NotifyTailChange(tailChange bool) {
 	if sev.fullNode.config.REMINE_ON_TAIL_CHANGE && tailChange {
 		sev.cmd <- commands.Command{
 			Op: commands.RESTART,
 		}
 	}
+}
+
+// Count a signal of out of sync. If we are not currently syncing and have seen too
+// many of them, we should try to sync with peer in a round robin manner.
+func (sev *FullNodeServer) CountOutOfSync() {
+	sev.m.Lock()
+	defer sev.m.Unlock()
+	if sev.syncing {
+		return
+	}
+	sev.blockFailure++
+	if sev.blockFailure >= int(sev.fullNode.config.CONFIRMATION) {
+		sev.cmd <- commands.Command{
+			Op: commands.SYNC,
+		}
+	}
+}
+
+// Keep the block whose parent is unknown in the orphan pool, and fetch its missing
+// ancestors from the sender in background. Once the ancestors are connected, the
+// orphan is connected as well. If the block can't be kept or the ancestors can't be
+// fetched, count it as out of sync, which eventually falls back to a full sync.
+func (sev *FullNodeServer) HandleOrphanBlock(b *model.Block, sender *service.NodeAddr) {
+	missing, added := sev.fullNode.AddOrphanBlock(b)
+	if !added || sender == nil {
+		sev.CountOutOfSync()
+		return
+	}
+	go func() {
+		err := sev.FetchAncestors(Address{IpAddr: sender.IpAddr, Port: sender.Port}, missing)
+		if err != nil {
+			sev.Log("fail to fetch ancestors of orphan block: " + b.Hash + " err: " + err.Error())
+			sev.CountOutOfSync()
+		}
+	}()
+}
+
+// Fetch the missing block with the given hash from the peer at addr, and keep fetching
+// its parents until one of them connects to the blockchain. Orphans are connected once
+// their parents are connected, see SetBlockInternal.
+func (sev *FullNodeServer) FetchAncestors(addr Address, hash string) error {
+	peer, err := sev.GetPeerByAddress(addr)
 	if err != nil {
-		sev.Log("fail to handle incoming block: " + req.Block.Hash + " err: " + err.Error())
+		return err
 	}
-	return res, nil
+	// Each fetched ancestor is kept as orphan, so there is no point to fetch more
+	// than the orphan pool can hold.
+	for i := 0; i < sev.fullNode.config.MAX_ORPHAN_BLOCKS; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		res, err := peer.client.GetBlock(ctx, &service.GetBlockRequest{Hash: hash})
+		cancel()
+		if err != nil {
+			return err
+		}
+		b := res.GetBlock()
+		if b == nil || b.Hash != hash {
+			return fmt.Errorf("peer %s doesn't have block: %s", peer, hash)
+		}
+		// Ancestors are old blocks that peers already know of, no need to broadcast.
+		_, tailChange, outOfSync, err := sev.SetBlockInternal(&service.SetBlockRequest{Block: b}, false /*broadcast=*/)
+		if err == nil {
+			sev.NotifyTailChange(tailChange)
+			return nil
+		}
+		if !outOfSync {
+			return err
+		}
+		missing, added := sev.fullNode.AddOrphanBlock(b)
+		if !added {
+			return errors.New("fail to keep ancestor in orphan pool: " + hash)
+		}
+		hash = missing
+	}
+	return errors.New("too many missing ancestors")
+}
+
+// Return the peer with the given address.
+func (sev *FullNodeServer) GetPeerByAddress(addr Address) (Peer, error) {
+	sev.m.RLock()
+	defer sev.m.RUnlock()
+	for i := 0; i < len(sev.peers); i++ {
+		if sev.peers[i].addr == addr {
+			return sev.peers[i], nil
+		}
+	}
+	return Peer{}, fmt.Errorf("peer not found: %s:%s", addr.IpAddr, addr.Port)
 }
 
 // In sync mode we don't want to broadcast the block to other nodes, in all other cases we do.
+// Once the block is added, orphans waiting for it are connected the same way.
 func (sev *FullNodeServer) SetBlockInternal(req *service.SetBlockRequest, broadcast bool) (*service.SetBlockResponse, bool, bool, error) {
 	block := req.Block
 	tailChange, outOfSync, err := sev.fullNode.HandleNewBlock(block)
@@ -371,33 +643,175 @@ func (sev *FullNodeServer) SetBlockInternal(req *service.SetBlockRequest, broadc
 	}
 
 	// Broadcast to all other nodes.
-	if !broadcast {
-		return &service.SetBlockResponse{}, tailChange, outOfSync, err
+	if broadcast {
+		sev.BroadcastBlock(block)
+	}
+
+	for _, child := range sev.fullNode.TakeOrphanChildren(block.Hash) {
+		_, childTailChange, _, err := sev.SetBlockInternal(&service.SetBlockRequest{Block: child}, broadcast)
+		if err != nil {
+			sev.Log("fail to connect orphan block: " + child.Hash + " err: " + err.Error())
+		}
+		tailChange = tailChange || childTailChange
 	}
 
+	return &service.SetBlockResponse{}, tailChange, outOfSync, nil
+}
+
+// Announce the block to all peers, each peer requests it if it doesn't have it yet.
+func (sev *FullNodeServer) BroadcastBlock(block *model.Block) {
+	sev.AnnounceInventory(&service.InvItem{Type: service.InvType_INV_BLOCK, Hash: block.Hash})
+}
+
+// Queue the inventory to be announced to all peers that don't know it yet. Each peer has
+// its own queue drained by its own goroutine, so that a slow peer can't stall others.
+func (sev *FullNodeServer) AnnounceInventory(item *service.InvItem) {
 	sev.m.RLock()
 	defer sev.m.RUnlock()
 	for i := 0; i < len(sev.peers); i++ {
 		peer := sev.peers[i]
+		if !peer.relay.push(item) {
+			sev.Log(fmt.Sprintf("announce queue of %s is full, drop %s", peer, item.Hash))
+		}
+	}
+}
+
+// Start sending announcements queued for the peer, with self as sender so that the
+// peer knows where to request the data from.
+func (sev *FullNodeServer) StartRelay(p Peer) {
+	sender := &service.NodeAddr{IpAddr: sev.addr.IpAddr, Port: sev.addr.Port}
+	go p.relay.run(func(items []*service.InvItem) {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		_, err := peer.client.SetBlock(ctx, &service.SetBlockRequest{Block: block})
+		_, err := p.client.Announce(ctx, &service.AnnounceRequest{Items: items, Sender: sender})
 		if err != nil {
-			sev.Log(err.Error())
+			sev.Log(fmt.Sprintf("fail to announce to %s: %s", p, err.Error()))
+		}
+	})
+}
+
+// Handle announced inventory, request the unknown items from the sender in background.
+func (sev *FullNodeServer) Announce(ctx context.Context, req *service.AnnounceRequest) (*service.AnnounceResponse, error) {
+	if req.Sender == nil {
+		return &service.AnnounceResponse{}, nil
+	}
+	peer, err := sev.GetPeerByAddress(Address{IpAddr: req.Sender.IpAddr, Port: req.Sender.Port})
+	if err != nil {
+		sev.Log("ignore announcement: " + err.Error())
+		return &service.AnnounceResponse{}, nil
+	}
+
+	unknown := []*service.InvItem{}
+	for _, item := range req.Items {
+		peer.relay.markKnown(item.Hash)
+		if sev.HasInventory(item) || !sev.MarkRequested(item.Hash) {
+			continue
+		}
+		unknown = append(unknown, item)
+	}
+	if len(unknown) > 0 {
+		go sev.RequestData(peer, unknown)
+	}
+	return &service.AnnounceResponse{}, nil
+}
+
+// Whether we already have the block or transaction.
+func (sev *FullNodeServer) HasInventory(item *service.InvItem) bool {
+	switch item.Type {
+	case service.InvType_INV_BLOCK:
+		return sev.fullNode.HasBlock(item.Hash)
+	case service.InvType_INV_TX:
+		_, exist := sev.fullNode.GetTransactionFromPool(item.Hash)
+		return exist
+	}
+	// Unknown type, pretend we have it so that it's never requested.
+	return true
+}
+
+// Mark the hash as being requested, return false if it's already being requested.
+func (sev *FullNodeServer) MarkRequested(hash string) bool {
+	sev.reqM.Lock()
+	defer sev.reqM.Unlock()
+	if sev.requested[hash] {
+		return false
+	}
+	sev.requested[hash] = true
+	return true
+}
+
+// Request the items from the peer and handle them. Blocks are handled before
+// transactions because transactions might spend outputs in the blocks.
+func (sev *FullNodeServer) RequestData(p Peer, items []*service.InvItem) {
+	defer func() {
+		sev.reqM.Lock()
+		defer sev.reqM.Unlock()
+		for _, item := range items {
+			delete(sev.requested, item.Hash)
 		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err := p.client.GetData(ctx, &service.GetDataRequest{Items: items})
+	if err != nil {
+		sev.Log(fmt.Sprintf("fail to get data from %s: %s", p, err.Error()))
+		return
+	}
+	sender := &service.NodeAddr{IpAddr: p.addr.IpAddr, Port: p.addr.Port}
+	for _, b := range res.Blocks {
+		sev.Log(fmt.Sprintf("received a new block: %s", b.Hash))
+		sev.HandleBlock(b, sender)
 	}
+	for _, tx := range res.Txs {
+		sev.HandleTransaction(tx)
+	}
+}
 
-	return &service.SetBlockResponse{}, tailChange, outOfSync, err
+// Return the requested blocks and transactions we have.
+func (sev *FullNodeServer) GetData(ctx context.Context, req *service.GetDataRequest) (*service.GetDataResponse, error) {
+	res := &service.GetDataResponse{}
+	for _, item := range req.Items {
+		switch item.Type {
+		case service.InvType_INV_BLOCK:
+			if b, exist := sev.fullNode.GetBlock(item.Hash); exist {
+				res.Blocks = append(res.Blocks, b)
+			}
+		case service.InvType_INV_TX:
+			if tx, exist := sev.fullNode.GetTransactionFromPool(item.Hash); exist {
+				res.Txs = append(res.Txs, tx)
+			}
+		}
+	}
+	return res, nil
+}
+
+// Return the block with the given hash on any branch, the block is nil if not found.
+func (sev *FullNodeServer) GetBlock(ctx context.Context, req *service.GetBlockRequest) (*service.GetBlockResponse, error) {
+	b, _ := sev.fullNode.GetBlock(req.Hash)
+	return &service.GetBlockResponse{Block: b}, nil
 }
 
 // Sync returns blocks it knows of starting from the given hash in request. If not found the block at all,
 // return from the first non-genesis block in the blockchain. This function only returns blocks from the
 // longest chain.
+//
+// Deprecated: SyncToLatest uses GetHeaders and GetBlocksByHash instead.
 func (sev *FullNodeServer) Sync(ctx context.Context, req *service.SyncRequest) (*service.SyncResponse, error) {
 	blocks, synced := sev.fullNode.GetBlocks(req.Hash, int(req.Number))
 	return &service.SyncResponse{Block: blocks, Synced: synced}, nil
 }
 
+// Return headers following the block locator in request on the main chain.
+func (sev *FullNodeServer) GetHeaders(ctx context.Context, req *service.GetHeadersRequest) (*service.GetHeadersResponse, error) {
+	headers := sev.fullNode.GetHeaders(req.Locator, req.StopHash, int(req.Number))
+	return &service.GetHeadersResponse{Headers: headers}, nil
+}
+
+// Return blocks with the hashes in request from any branch.
+func (sev *FullNodeServer) GetBlocksByHash(ctx context.Context, req *service.GetBlocksByHashRequest) (*service.GetBlocksByHashResponse, error) {
+	return &service.GetBlocksByHashResponse{Blocks: sev.fullNode.GetBlocksByHash(req.Hashes)}, nil
+}
+
 // Return all peers this full node knows of.
 func (sev *FullNodeServer) GetPeers(ctx context.Context, req *service.GetPeersRequest) (*service.GetPeersResponse, error) {
 	sev.m.RLock()
@@ -452,16 +866,31 @@ func (sev *FullNodeServer) Log(s string) {
 	})
 }
 
+// Remove expired transactions from the pool every POOL_EXPIRY_INTERVAL seconds, forever.
+func (sev *FullNodeServer) RunPoolExpiry() {
+	ticker := time.NewTicker(time.Duration(sev.fullNode.config.POOL_EXPIRY_INTERVAL) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		sev.fullNode.ExpireTransactions()
+	}
+}
+
 // Create a new full node server with connection established. Exit if connection
 // cannot be established.
-func NewFullNodeServer(c config.AppConfig, ps []Peer, addr Address, keyPath string, cmd chan commands.Command, g *gocui.Gui) *FullNodeServer {
+func NewFullNodeServer(c config.AppConfig, ps []Peer, addr Address, keyPath string, store storage.BlockStore, cmd chan commands.Command, g *gocui.Gui) *FullNodeServer {
 	sev := FullNodeServer{
-		fullNode: NewFullNode(c, keyPath),
+		fullNode: NewFullNode(c, keyPath, store),
 		peers:    ps,
 		cmd:      cmd,
 		addr:     addr,
 		m:        sync.RWMutex{},
 		g:        g,
+
+		requested: make(map[string]bool),
+	}
+	sev.fullNode.logger = sev.Log
+	if c.POOL_EXPIRY_INTERVAL > 0 {
+		go sev.RunPoolExpiry()
 	}
 	for i := 0; i < len(ps); i++ {
 		peer := ps[i]
@@ -475,6 +904,8 @@ func NewFullNodeServer(c config.AppConfig, ps []Peer, addr Address, keyPath stri
 		defer conn.Close()
 		client := service.NewFullNodeServiceClient(conn)
 		sev.peers[i].client = client
+		sev.peers[i].relay = newPeerRelay()
+		sev.StartRelay(sev.peers[i])
 	}
 	return &sev
 }
@@ -0,0 +1,233 @@
+package full_node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Luismorlan/btc_in_go/commands"
+	"github.com/Luismorlan/btc_in_go/config"
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"github.com/Luismorlan/btc_in_go/storage"
+	"github.com/Luismorlan/btc_in_go/utils"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func createTestConfig() config.AppConfig {
+	return config.AppConfig{
+		DIFFICULTY:            1,
+		COINBASE_REWARD:       utils.COIN,
+		CONFIRMATION:          100,
+		KEY_TYPE:              "ed25519",
+		RETARGET_INTERVAL:     2,
+		TARGET_BLOCK_INTERVAL: 100,
+		MAX_RETARGET_STEP:     4,
+	}
+}
+
+func createTestFullNode(t *testing.T, c config.AppConfig) *FullNode {
+	dir, err := ioutil.TempDir("", "full_node")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return NewFullNode(c, filepath.Join(dir, "key.pem"), storage.NewMemoryBlockStore())
+}
+
+// Mine an empty block on top of parent with the given timestamp, and hand it to full node.
+func mineTestBlock(t *testing.T, f *FullNode, parent *model.BlockWrapper, timestamp int64) *model.BlockWrapper {
+	bits := utils.GetNextDifficulty(parent, f.config)
+	block, _, _, err := utils.CreateNewBlock([]*model.Transaction{}, parent.B.Hash, utils.GetBlockSubsidy(parent.Height+1, f.config), f.getValidationContext(parent),
+		f.keys.Public(), model.NewLedger(), int(bits), timestamp, make(chan commands.Command))
+	assert.Nil(t, err)
+	_, _, err = f.HandleNewBlock(block)
+	assert.Nil(t, err)
+	return f.blockchain.Chain[block.Hash]
+}
+
+// Mine n blocks on top of parent, each interval seconds after the previous one.
+func mineTestBranch(t *testing.T, f *FullNode, parent *model.BlockWrapper, n int, start int64, interval int64) *model.BlockWrapper {
+	for i := 0; i < n; i++ {
+		parent = mineTestBlock(t, f, parent, start+int64(i)*interval)
+	}
+	return parent
+}
+
+func TestForkChoiceMostWork(t *testing.T) {
+	f := createTestFullNode(t, createTestConfig())
+	genesis := f.GetTail()
+	start := time.Now().Unix() - 10000
+
+	// A longer branch with blocks on time keeps the initial difficulty.
+	long := mineTestBranch(t, f, genesis, 5, start, 100)
+	assert.Equal(t, long, f.GetTail())
+
+	// A shorter branch with blocks coming too fast has its difficulty retargeted up at
+	// height 4, thus has more work although it's shorter.
+	short := mineTestBranch(t, f, genesis, 4, start+50, 1)
+	assert.True(t, short.Height < long.Height)
+	assert.True(t, short.TotalWork.Cmp(long.TotalWork) > 0)
+	assert.Equal(t, short, f.GetTail())
+
+	// The UTXO set follows the tail: only coinbase outputs of the short branch exist.
+	assert.Equal(t, 4, len(f.GetLedgerSnapshotAtDepth(0).L))
+	for utxo := range f.GetLedgerSnapshotAtDepth(0).L {
+		found := false
+		for b := short; b.Parent != nil; b = b.Parent {
+			found = found || b.B.Coinbase.Hash == utxo.PrevTxHash
+		}
+		assert.True(t, found)
+	}
+}
+
+func TestForkChoiceTieBreak(t *testing.T) {
+	f := createTestFullNode(t, createTestConfig())
+	genesis := f.GetTail()
+	start := time.Now().Unix() - 10000
+
+	a := mineTestBlock(t, f, genesis, start)
+	b := mineTestBlock(t, f, genesis, start+1)
+	assert.Equal(t, 0, a.TotalWork.Cmp(b.TotalWork))
+
+	// Equal work is resolved by the smaller hash, regardless of arrival order.
+	expected := a
+	if b.B.Hash < a.B.Hash {
+		expected = b
+	}
+	assert.Equal(t, expected, f.GetTail())
+
+	g := createTestFullNode(t, createTestConfig())
+	_, _, err := g.HandleNewBlock(b.B)
+	assert.Nil(t, err)
+	_, _, err = g.HandleNewBlock(a.B)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.B.Hash, g.GetTail().B.Hash)
+}
+
+// Mine a block with the given transactions on top of parent, and hand it to full node.
+func mineTestBlockWithTxs(t *testing.T, f *FullNode, parent *model.BlockWrapper, timestamp int64, txs []*model.Transaction) *model.BlockWrapper {
+	l := utils.GetLedgerAtBlock(f.blockchain, parent)
+	bits := utils.GetNextDifficulty(parent, f.config)
+	block, _, _, err := utils.CreateNewBlock(txs, parent.B.Hash, utils.GetBlockSubsidy(parent.Height+1, f.config), f.getValidationContext(parent),
+		f.keys.Public(), l, int(bits), timestamp, make(chan commands.Command))
+	assert.Nil(t, err)
+	_, _, err = f.HandleNewBlock(block)
+	assert.Nil(t, err)
+	return f.blockchain.Chain[block.Hash]
+}
+
+// Create a transaction spending the coinbase of the given block to a random receiver.
+func createTestSpend(t *testing.T, f *FullNode, b *model.BlockWrapper, value int64) *model.Transaction {
+	receiver, _ := utils.GenerateKey(model.KeyType_ED25519, 0)
+	utxo := model.UTXOLite{PrevTxHash: b.B.Coinbase.Hash, Index: 0}
+	tx, err := utils.CreatePendingTransaction(f.keys, map[model.UTXOLite]*model.Output{utxo: b.B.Coinbase.Outputs[0]},
+		[]*model.Output{utils.NewOutput(value, receiver.Public())}, 0 /*fee=*/)
+	assert.Nil(t, err)
+	return tx
+}
+
+func TestReorgReconcilesTxPool(t *testing.T) {
+	c := createTestConfig()
+	c.RETARGET_INTERVAL = 0
+	f := createTestFullNode(t, c)
+	start := time.Now().Unix() - 10000
+	base := mineTestBlock(t, f, f.GetTail(), start)
+
+	// Branch A confirms tx1.
+	tx1 := createTestSpend(t, f, base, 1)
+	a := mineTestBlockWithTxs(t, f, base, start+1, []*model.Transaction{tx1})
+	assert.Equal(t, a, f.GetTail())
+
+	// Branch B overtakes A without tx1, tx1 goes back to pool. tx2 double spending tx1
+	// can't enter the pool.
+	b := mineTestBranch(t, f, base, 2, start+2, 1)
+	assert.Equal(t, b, f.GetTail())
+	assert.Contains(t, f.txPool.TxPool, tx1.Hash)
+	tx2 := createTestSpend(t, f, base, 2)
+	assert.NotNil(t, f.AddTransactionToPool(tx2))
+
+	// Branch C overtakes B and confirms tx2, tx1 now conflicts and is evicted.
+	c1 := mineTestBlockWithTxs(t, f, base, start+5, []*model.Transaction{tx2})
+	mineTestBranch(t, f, c1, 2, start+6, 1)
+	assert.Equal(t, int64(4), f.GetHeight())
+	assert.Equal(t, 0, len(f.txPool.TxPool))
+}
+
+func TestCoinbaseMaturity(t *testing.T) {
+	c := createTestConfig()
+	c.RETARGET_INTERVAL = 0
+	c.COINBASE_MATURITY = 3
+	f := createTestFullNode(t, c)
+	start := time.Now().Unix() - 10000
+	base := mineTestBlock(t, f, f.GetTail(), start)
+	tx := createTestSpend(t, f, base, 1)
+
+	// A block at height 2 can't spend the coinbase of height 1.
+	block, _, _, err := utils.CreateNewBlock([]*model.Transaction{tx}, base.B.Hash, utils.GetBlockSubsidy(2, c), utils.ValidationContext{Height: 2},
+		f.keys.Public(), utils.GetLedgerAtBlock(f.blockchain, base), c.DIFFICULTY, start+1, make(chan commands.Command))
+	assert.Nil(t, err)
+	_, _, err = f.HandleNewBlock(block)
+	assert.NotNil(t, err)
+
+	// Nor can the pool accept it until the next block is at height 4.
+	tail := mineTestBlock(t, f, base, start+1)
+	assert.NotNil(t, f.AddTransactionToPool(tx))
+	mineTestBlock(t, f, tail, start+2)
+	assert.Nil(t, f.AddTransactionToPool(tx))
+}
+
+func TestWitnessRoot(t *testing.T) {
+	c := createTestConfig()
+	c.RETARGET_INTERVAL = 0
+	f := createTestFullNode(t, c)
+	start := time.Now().Unix() - 10000
+	base := mineTestBlock(t, f, f.GetTail(), start)
+	tx := createTestSpend(t, f, base, 1)
+	block, _, _, err := utils.CreateNewBlock([]*model.Transaction{tx}, base.B.Hash, utils.GetBlockSubsidy(2, c), f.getValidationContext(base),
+		f.keys.Public(), utils.GetLedgerAtBlock(f.blockchain, base), c.DIFFICULTY, start+1, make(chan commands.Command))
+	assert.Nil(t, err)
+
+	// Replacing the unlocking script keeps the transaction hash, but not the witness root.
+	tampered := proto.Clone(block).(*model.Block)
+	tampered.Txs[0].Inputs[0].Script = script.AppendData(nil, []byte{utils.SIGHASH_ALL})
+	assert.Nil(t, utils.FillTxHash(tampered.Txs[0]))
+	assert.Equal(t, tx.Hash, tampered.Txs[0].Hash)
+	_, _, err = f.HandleNewBlock(tampered)
+	assert.Contains(t, err.Error(), "witness root")
+
+	_, _, err = f.HandleNewBlock(block)
+	assert.Nil(t, err)
+}
+
+func TestLockTime(t *testing.T) {
+	c := createTestConfig()
+	c.RETARGET_INTERVAL = 0
+	f := createTestFullNode(t, c)
+	start := time.Now().Unix() - 10000
+	base := mineTestBlock(t, f, f.GetTail(), start)
+	tx := createTestSpend(t, f, base, 1)
+	tx.LockTime = start + 1
+	assert.Nil(t, utils.SignInput(f.keys, tx, 0, base.B.Coinbase.Outputs[0], utils.SIGHASH_ALL))
+	assert.Nil(t, utils.FillTxHash(tx))
+
+	// A block whose parent has median time past start can't include it.
+	block, _, _, err := utils.CreateNewBlock([]*model.Transaction{tx}, base.B.Hash, utils.GetBlockSubsidy(2, c), utils.ValidationContext{Height: 2, MedianTimePast: start + 1},
+		f.keys.Public(), utils.GetLedgerAtBlock(f.blockchain, base), c.DIFFICULTY, start+1, make(chan commands.Command))
+	assert.Nil(t, err)
+	_, _, err = f.HandleNewBlock(block)
+	assert.NotNil(t, err)
+
+	// Nor can the pool accept it until median time past reaches the lock time, then the
+	// block template has it.
+	tail := mineTestBlock(t, f, base, start+1)
+	assert.NotNil(t, f.AddTransactionToPool(tx))
+	mineTestBlock(t, f, tail, start+2)
+	assert.Nil(t, f.AddTransactionToPool(tx))
+	block, _, err = f.CreateNewBlock(make(chan commands.Command), 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.Transaction{tx}, block.Txs)
+	_, _, err = f.HandleNewBlock(block)
+	assert.Nil(t, err)
+}
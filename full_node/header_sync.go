@@ -0,0 +1,91 @@
+package full_node
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/utils"
+)
+
+// Headers downloaded during headers-first sync whose blocks are not in the blockchain yet.
+// Each header is kept in a wrapper without body, linked to its parent which is either
+// another downloaded header or a block in the blockchain. This way difficulty, median time
+// past and total work are computed the same way as for blocks.
+type headerChain struct {
+	f *FullNode
+	// A map from block hash to the wrapper of the downloaded header.
+	headers map[string]*model.BlockWrapper
+	// The downloaded header with the most total work, nil if nothing is downloaded.
+	best *model.BlockWrapper
+}
+
+func newHeaderChain(f *FullNode) *headerChain {
+	return &headerChain{
+		f:       f,
+		headers: make(map[string]*model.BlockWrapper),
+	}
+}
+
+// Return the wrapper with the given hash from the downloaded headers or the blockchain.
+func (h *headerChain) get(hash string) (*model.BlockWrapper, bool) {
+	if bw, exist := h.headers[hash]; exist {
+		return bw, true
+	}
+	return h.f.GetBlockWrapper(hash)
+}
+
+// Validate the header against its parent and add it. Return the wrapper of the header,
+// which might be already known. now is the local unix time.
+func (h *headerChain) add(header *model.BlockHeader, now int64) (*model.BlockWrapper, error) {
+	hash, err := utils.GetBlockHeaderHash(header)
+	if err != nil {
+		return nil, err
+	}
+	if bw, exist := h.get(hash); exist {
+		return bw, nil
+	}
+	err = utils.CheckBlockHeader(hash, header, now)
+	if err != nil {
+		return nil, err
+	}
+	parent, exist := h.get(header.PrevHash)
+	if !exist {
+		return nil, errors.New("header doesn't connect to known headers: " + hash)
+	}
+	err = utils.CheckBlockHeaderContext(header, parent, h.f.config)
+	if err != nil {
+		return nil, err
+	}
+
+	bw := &model.BlockWrapper{
+		B:         &model.Block{Hash: hash, Header: header},
+		Parent:    parent,
+		Height:    parent.Height + 1,
+		TotalWork: new(big.Int).Add(parent.TotalWork, utils.GetBlockWork(header.Bits)),
+	}
+	h.headers[hash] = bw
+	if h.best == nil || utils.IsBetterTip(bw, h.best) {
+		h.best = bw
+	}
+	return bw, nil
+}
+
+// Return hashes of the blocks to download to reach the best header, ranked by height from
+// the least to the most. Return nothing if the best header doesn't beat the tail.
+func (h *headerChain) getMissingBlocks() []string {
+	if h.best == nil || !utils.IsBetterTip(h.best, h.f.GetTail()) {
+		return []string{}
+	}
+	hashes := []string{}
+	for bw := h.best; bw != nil; bw = bw.Parent {
+		if _, exist := h.f.GetBlockWrapper(bw.B.Hash); exist {
+			break
+		}
+		hashes = append(hashes, bw.B.Hash)
+	}
+	for i, j := 0, len(hashes)-1; i < j; i, j = i+1, j-1 {
+		hashes[i], hashes[j] = hashes[j], hashes[i]
+	}
+	return hashes
+}
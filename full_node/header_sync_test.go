@@ -0,0 +1,67 @@
+package full_node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/utils"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetHeaders(t *testing.T) {
+	f := createTestFullNode(t, createTestConfig())
+	genesis := f.GetTail()
+	start := time.Now().Unix() - 10000
+	side := mineTestBranch(t, f, genesis, 2, start, 1000)
+	tail := mineTestBranch(t, f, genesis, 5, start+1, 100)
+	assert.Equal(t, tail, f.GetTail())
+
+	// A locator from the side branch only matches genesis on the main chain.
+	headers := f.GetHeaders(utils.GetBlockLocator(side), "", 0)
+	assert.Equal(t, 5, len(headers))
+	assert.True(t, proto.Equal(utils.GetAncestor(tail, 1).B.Header, headers[0]))
+	assert.True(t, proto.Equal(tail.B.Header, headers[4]))
+
+	// Headers after the locator, bounded by number and stop hash.
+	locator := utils.GetBlockLocator(utils.GetAncestor(tail, 2))
+	assert.Equal(t, 3, len(f.GetHeaders(locator, "", 0)))
+	assert.Equal(t, 2, len(f.GetHeaders(locator, "", 2)))
+	assert.Equal(t, 1, len(f.GetHeaders(locator, utils.GetAncestor(tail, 3).B.Hash, 0)))
+	assert.Equal(t, 0, len(f.GetHeaders(utils.GetBlockLocator(tail), "", 0)))
+}
+
+func TestHeaderChain(t *testing.T) {
+	f := createTestFullNode(t, createTestConfig())
+	g := createTestFullNode(t, createTestConfig())
+	start := time.Now().Unix() - 10000
+	tail := mineTestBranch(t, f, f.GetTail(), 5, start, 100)
+
+	// g downloads headers, then the missing blocks in order.
+	h := newHeaderChain(g)
+	now := time.Now().Unix()
+	for _, header := range f.GetHeaders(utils.GetBlockLocator(g.GetTail()), "", 0) {
+		_, err := h.add(header, now)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, tail.B.Hash, h.best.B.Hash)
+	assert.Equal(t, 0, tail.TotalWork.Cmp(h.best.TotalWork))
+	hashes := h.getMissingBlocks()
+	assert.Equal(t, 5, len(hashes))
+	for _, b := range f.GetBlocksByHash(hashes[:3]) {
+		_, _, err := g.HandleNewBlock(b)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, hashes[3:], h.getMissingBlocks())
+
+	// Headers must connect and follow the consensus rules.
+	bad := proto.Clone(tail.B.Header).(*model.BlockHeader)
+	bad.PrevHash = utils.BytesToHex(utils.SHA256([]byte("unknown")))
+	_, err := newHeaderChain(g).add(bad, now)
+	assert.NotNil(t, err)
+	bad = proto.Clone(utils.GetAncestor(tail, 1).B.Header).(*model.BlockHeader)
+	bad.Timestamp = 0
+	_, err = newHeaderChain(g).add(bad, now)
+	assert.NotNil(t, err)
+}
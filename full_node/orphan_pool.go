@@ -0,0 +1,105 @@
+package full_node
+
+import (
+	"github.com/Luismorlan/btc_in_go/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// Orphan blocks are valid looking blocks whose parent is not in the blockchain yet. They
+// are kept until their parent arrives, bounded by count and total size in bytes. When
+// full, the oldest orphans are evicted first.
+type orphanPool struct {
+	// A map from block hash to the orphan block.
+	blocks map[string]*model.Block
+	// A map from the missing parent hash to hashes of its orphan children.
+	byParent map[string][]string
+	// Orphan hashes in the order they were added, used for eviction.
+	order []string
+	// Total size in bytes of all orphans.
+	size int64
+
+	maxCount int
+	maxBytes int64
+}
+
+func newOrphanPool(maxCount int, maxBytes int64) *orphanPool {
+	return &orphanPool{
+		blocks:   make(map[string]*model.Block),
+		byParent: make(map[string][]string),
+		maxCount: maxCount,
+		maxBytes: maxBytes,
+	}
+}
+
+// Add an orphan block, return false if it already exists or can never fit into the pool.
+func (p *orphanPool) add(b *model.Block) bool {
+	if _, exist := p.blocks[b.Hash]; exist {
+		return false
+	}
+	size := int64(proto.Size(b))
+	if p.maxCount <= 0 || size > p.maxBytes {
+		return false
+	}
+	for len(p.blocks) >= p.maxCount || p.size+size > p.maxBytes {
+		p.remove(p.order[0])
+	}
+
+	parent := b.GetHeader().GetPrevHash()
+	p.blocks[b.Hash] = b
+	p.byParent[parent] = append(p.byParent[parent], b.Hash)
+	p.order = append(p.order, b.Hash)
+	p.size += size
+	return true
+}
+
+func (p *orphanPool) has(hash string) bool {
+	_, exist := p.blocks[hash]
+	return exist
+}
+
+func (p *orphanPool) remove(hash string) {
+	b, exist := p.blocks[hash]
+	if !exist {
+		return
+	}
+	delete(p.blocks, hash)
+	p.size -= int64(proto.Size(b))
+
+	parent := b.GetHeader().GetPrevHash()
+	p.byParent[parent] = removeString(p.byParent[parent], hash)
+	if len(p.byParent[parent]) == 0 {
+		delete(p.byParent, parent)
+	}
+	p.order = removeString(p.order, hash)
+}
+
+// Remove and return all orphans whose parent is the given block.
+func (p *orphanPool) takeChildren(parent string) []*model.Block {
+	children := []*model.Block{}
+	for _, hash := range append([]string{}, p.byParent[parent]...) {
+		children = append(children, p.blocks[hash])
+		p.remove(hash)
+	}
+	return children
+}
+
+// Follow the orphan's ancestors within the pool, and return the hash of the first
+// ancestor that is missing from the pool.
+func (p *orphanPool) getMissingAncestor(hash string) string {
+	for {
+		b, exist := p.blocks[hash]
+		if !exist {
+			return hash
+		}
+		hash = b.GetHeader().GetPrevHash()
+	}
+}
+
+func removeString(ss []string, s string) []string {
+	for i := 0; i < len(ss); i++ {
+		if ss[i] == s {
+			return append(ss[:i], ss[i+1:]...)
+		}
+	}
+	return ss
+}
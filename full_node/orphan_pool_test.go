@@ -0,0 +1,48 @@
+package full_node
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func createTestOrphan(hash string, prevHash string) *model.Block {
+	return &model.Block{
+		Hash:   hash,
+		Header: &model.BlockHeader{PrevHash: prevHash},
+	}
+}
+
+func TestOrphanPool(t *testing.T) {
+	p := newOrphanPool(2, 1000)
+	b1 := createTestOrphan("b1", "b0")
+	b2 := createTestOrphan("b2", "b1")
+	b3 := createTestOrphan("b3", "b1")
+	assert.True(t, p.add(b2))
+	assert.False(t, p.add(b2))
+	assert.True(t, p.add(b1))
+	assert.Equal(t, "b0", p.getMissingAncestor("b2"))
+
+	// The oldest orphan is evicted when the pool is full.
+	assert.True(t, p.add(b3))
+	assert.False(t, p.has("b2"))
+	assert.Equal(t, int64(proto.Size(b1)+proto.Size(b3)), p.size)
+
+	assert.Equal(t, []*model.Block{b3}, p.takeChildren("b1"))
+	assert.False(t, p.has("b3"))
+	assert.Equal(t, 0, len(p.takeChildren("b1")))
+}
+
+func TestOrphanPoolBytesLimit(t *testing.T) {
+	b1 := createTestOrphan("b1", "b0")
+	b2 := createTestOrphan("b2", "b1")
+	p := newOrphanPool(10, int64(proto.Size(b1)))
+	assert.True(t, p.add(b1))
+	assert.True(t, p.add(b2))
+	assert.False(t, p.has("b1"))
+
+	p = newOrphanPool(0, 1000)
+	assert.False(t, p.add(b1))
+}
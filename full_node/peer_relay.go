@@ -0,0 +1,106 @@
+package full_node
+
+import (
+	"sync"
+
+	"github.com/Luismorlan/btc_in_go/service"
+)
+
+// Maximum number of inventory hashes remembered per peer, the oldest are forgotten first.
+const MAX_KNOWN_INVENTORY = 10000
+
+// Maximum number of announcements waiting to be sent to a peer. Announcements to a peer
+// whose queue is full are dropped, so that a slow peer can't stall relaying to others.
+const MAX_ANNOUNCE_QUEUE = 1000
+
+// Maximum number of inventory items sent in a single announcement.
+const MAX_INV_PER_ANNOUNCE = 500
+
+// Relay state of a peer: the inventory the peer is known to have, and the announcements
+// waiting to be sent to it by a dedicated goroutine.
+type peerRelay struct {
+	m sync.Mutex
+	// Hashes of blocks and transactions the peer is known to have, because either we
+	// announced to it or it announced to us.
+	known map[string]bool
+	// Known hashes in the order they were added, used for eviction.
+	knownOrder []string
+
+	queue     chan *service.InvItem
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newPeerRelay() *peerRelay {
+	return &peerRelay{
+		known: make(map[string]bool),
+		queue: make(chan *service.InvItem, MAX_ANNOUNCE_QUEUE),
+		done:  make(chan struct{}),
+	}
+}
+
+// The caller must hold the lock.
+func (r *peerRelay) markKnownLocked(hash string) {
+	if r.known[hash] {
+		return
+	}
+	if len(r.knownOrder) >= MAX_KNOWN_INVENTORY {
+		delete(r.known, r.knownOrder[0])
+		r.knownOrder = r.knownOrder[1:]
+	}
+	r.known[hash] = true
+	r.knownOrder = append(r.knownOrder, hash)
+}
+
+// Remember the peer has the block or transaction with the given hash.
+func (r *peerRelay) markKnown(hash string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.markKnownLocked(hash)
+}
+
+// Queue the item to be announced unless the peer already knows it. Return false if the
+// item is dropped because the queue is full.
+func (r *peerRelay) push(item *service.InvItem) bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.known[item.Hash] {
+		return true
+	}
+	select {
+	case r.queue <- item:
+		r.markKnownLocked(item.Hash)
+		return true
+	default:
+		return false
+	}
+}
+
+// Send queued announcements with send until stopped. Items queued while sending are
+// batched into the next announcement.
+func (r *peerRelay) run(send func(items []*service.InvItem)) {
+	for {
+		var items []*service.InvItem
+		select {
+		case <-r.done:
+			return
+		case item := <-r.queue:
+			items = append(items, item)
+		}
+	drain:
+		for len(items) < MAX_INV_PER_ANNOUNCE {
+			select {
+			case item := <-r.queue:
+				items = append(items, item)
+			default:
+				break drain
+			}
+		}
+		send(items)
+	}
+}
+
+// Stop sending announcements, it's safe to call more than once.
+func (r *peerRelay) stop() {
+	r.closeOnce.Do(func() { close(r.done) })
+}
@@ -0,0 +1,52 @@
+package full_node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerRelayPush(t *testing.T) {
+	r := newPeerRelay()
+	item := &service.InvItem{Type: service.InvType_INV_BLOCK, Hash: "b1"}
+	assert.True(t, r.push(item))
+	assert.True(t, r.push(item))
+	assert.Equal(t, 1, len(r.queue))
+
+	// Items the peer announced to us are never announced back.
+	r.markKnown("t1")
+	assert.True(t, r.push(&service.InvItem{Type: service.InvType_INV_TX, Hash: "t1"}))
+	assert.Equal(t, 1, len(r.queue))
+
+	// Announcements are dropped instead of blocking once the queue is full.
+	for i := 1; i < MAX_ANNOUNCE_QUEUE; i++ {
+		assert.True(t, r.push(&service.InvItem{Hash: fmt.Sprint(i)}))
+	}
+	assert.False(t, r.push(&service.InvItem{Hash: "dropped"}))
+	assert.False(t, r.known["dropped"])
+}
+
+func TestPeerRelayRun(t *testing.T) {
+	r := newPeerRelay()
+	for i := 0; i < MAX_INV_PER_ANNOUNCE+1; i++ {
+		r.push(&service.InvItem{Hash: fmt.Sprint(i)})
+	}
+	sent := make(chan []*service.InvItem)
+	go r.run(func(items []*service.InvItem) { sent <- items })
+	assert.Equal(t, MAX_INV_PER_ANNOUNCE, len(<-sent))
+	assert.Equal(t, 1, len(<-sent))
+	r.stop()
+	r.stop()
+}
+
+func TestPeerRelayKnownLimit(t *testing.T) {
+	r := newPeerRelay()
+	for i := 0; i <= MAX_KNOWN_INVENTORY; i++ {
+		r.markKnown(fmt.Sprint(i))
+	}
+	assert.Equal(t, MAX_KNOWN_INVENTORY, len(r.known))
+	assert.False(t, r.known["0"])
+	assert.True(t, r.known[fmt.Sprint(MAX_KNOWN_INVENTORY)])
+}
@@ -3,13 +3,13 @@ module github.com/Luismorlan/btc_in_go
 go 1.16
 
 require (
-	github.com/jroimartin/gocui v0.4.0 // indirect
+	github.com/jroimartin/gocui v0.4.0
 	github.com/nsf/termbox-go v1.1.1 // indirect
-	github.com/satori/go.uuid v1.2.0 // indirect
+	github.com/satori/go.uuid v1.2.0
 	github.com/stretchr/objx v0.3.0 // indirect
-	github.com/stretchr/testify v1.7.0 // indirect
-	google.golang.org/grpc v1.37.1 // indirect
-	google.golang.org/protobuf v1.26.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/grpc v1.37.1
+	google.golang.org/protobuf v1.26.0
+	gopkg.in/yaml.v2 v2.4.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
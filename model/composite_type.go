@@ -1,5 +1,7 @@
 package model
 
+import "math/big"
+
 /*
 This file define composite type that is a combination of different structs.
 */
@@ -35,6 +37,38 @@ func GetUtxo(utxoLite *UTXOLite) UTXO {
 // maintain a ledger.
 type Ledger struct {
 	L map[UTXOLite]*Output
+	// Metadata of UTXOs created by blocks. A ledger of pending transactions may have UTXOs
+	// without metadata.
+	Meta map[UTXOLite]UTXOMeta
+}
+
+// Where an UTXO comes from, needed to enforce coinbase maturity and relative locks.
+type UTXOMeta struct {
+	// Height of the block creating the output.
+	Height int64
+	// Median time past of the parent of the block creating the output.
+	Time int64
+	// Whether the output is created by a coinbase transaction.
+	Coinbase bool
+}
+
+// UndoEntry is a single UTXO together with the output it references.
+type UndoEntry struct {
+	Utxo   UTXOLite
+	Output *Output
+	// Metadata of the UTXO, nil if it has none.
+	Meta *UTXOMeta
+}
+
+// BlockUndo records how a block changed the ledger of its parent, so that the ledger
+// can be rolled back to the parent or rolled forward to the block without storing a
+// full ledger for every block. Outputs both created and spent within the same block
+// are in neither list.
+type BlockUndo struct {
+	// UTXOs spent by the block, they must be restored when rolling back.
+	Spent []UndoEntry
+	// UTXOs created by the block, they must be removed when rolling back.
+	Created []UndoEntry
 }
 
 // BlockWrapper stores both the block information and it's metadata on blockchain.
@@ -47,49 +81,92 @@ type BlockWrapper struct {
 	Parent *BlockWrapper
 	// height in the blockchain.
 	Height int64
-	// Ledger at that node.
-	L *Ledger
+	// How this block changed the ledger of its parent.
+	Undo *BlockUndo
+	// Total work of the chain from genesis to this block, the tail is the block with the
+	// most total work.
+	TotalWork *big.Int
 }
 
 type Blockchain struct {
-	// The block with the maximum height
+	// The block with the most total work.
 	Tail *BlockWrapper
 	// A map from hex string of the block hash to block wrapper.
 	Chain map[string]*BlockWrapper
+	// The UTXO set at the tail. Ledger at any other block is derived from it by
+	// rolling back and forward using undo records.
+	UTXOSet *Ledger
 }
 
 // Create a new blockchain
 func NewBlockChain() *Blockchain {
-	// Create a genesis block that has only hash "00"
+	return NewBlockChainFromGenesis(GENESIS_HASH)
+}
+
+// Create a new blockchain whose genesis block has only the given hash.
+func NewBlockChainFromGenesis(hash string) *Blockchain {
 	genesisBlock := Block{
-		Hash: GENESIS_HASH,
+		Hash: hash,
 	}
 	genesisBlockWrapper := BlockWrapper{
-		B:      &genesisBlock,
-		Height: 0,
-		L:      NewLedger(),
+		B:         &genesisBlock,
+		Height:    0,
+		Undo:      &BlockUndo{},
+		TotalWork: big.NewInt(0),
 	}
 	return &Blockchain{
-		Tail:  &genesisBlockWrapper,
-		Chain: map[string]*BlockWrapper{GENESIS_HASH: &genesisBlockWrapper},
+		Tail:    &genesisBlockWrapper,
+		Chain:   map[string]*BlockWrapper{hash: &genesisBlockWrapper},
+		UTXOSet: NewLedger(),
 	}
 }
 
 func NewLedger() *Ledger {
 	return &Ledger{
-		L: make(map[UTXOLite]*Output),
+		L:    make(map[UTXOLite]*Output),
+		Meta: make(map[UTXOLite]UTXOMeta),
 	}
 }
 
+// A pending transaction in the pool, together with its dependencies on other pending
+// transactions.
+type TxPoolEntry struct {
+	Tx *Transaction
+	// Transaction fee in base units.
+	Fee int64
+	// Serialized size of the transaction in bytes.
+	Size int64
+	// Unix time the transaction entered the pool.
+	Time int64
+	// Hashes of pool transactions whose outputs this transaction spends.
+	Parents map[string]bool
+	// Hashes of pool transactions spending outputs of this transaction.
+	Children map[string]bool
+}
+
 type TransactionPool struct {
 	// TransactionPool contains all pending transactions that haven't be checked in the blockchain.
-	// Key is the hex of transaction's hash, value is the transaction.
-	TxPool map[string]*Transaction
+	// Key is the hex of transaction's hash, value is the pool entry of the transaction.
+	TxPool map[string]*TxPoolEntry
+	// Overlay on top of the UTXO set at tail: outputs created by pool transactions.
+	Outputs map[UTXOLite]*Output
+	// A map from UTXO, either confirmed or created in the pool, to the hash of the pool
+	// transaction spending it.
+	Spends map[UTXOLite]string
+	// Total serialized size of all pool transactions in bytes.
+	Size int64
+	// Minimum fee rate in base units per 1000 bytes to enter the pool, which rises when
+	// transactions are evicted because the pool is full, and decays over time.
+	MinFeeRate int64
+	// Unix time MinFeeRate was last raised or decayed.
+	MinFeeRateTime int64
 }
 
 // NewTransactionPool creates a new transaction pool with no transaction at all.
 func NewTransactionPool() *TransactionPool {
 	return &TransactionPool{
-		TxPool: make(map[string]*Transaction),
+		TxPool:  make(map[string]*TxPoolEntry),
+		Outputs: make(map[UTXOLite]*Output),
+		Spends:  make(map[UTXOLite]string),
 	}
 }
@@ -0,0 +1,332 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.15.8
+// source: model/psbt.proto
+
+package model
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Signature of an input by one of the keys of its redeem script.
+type PartialSignature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Public key encoded by utils.EncodePublicKey.
+	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// Signature followed by 1 byte of signature hash type.
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *PartialSignature) Reset() {
+	*x = PartialSignature{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_model_psbt_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartialSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartialSignature) ProtoMessage() {}
+
+func (x *PartialSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_model_psbt_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartialSignature.ProtoReflect.Descriptor instead.
+func (*PartialSignature) Descriptor() ([]byte, []int) {
+	return file_model_psbt_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PartialSignature) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *PartialSignature) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// What co-signers need to sign an input, and the signatures collected so far.
+type PartialInput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Output spent by the input, which pays to the hash of redeem_script.
+	Spent *Output `protobuf:"bytes,1,opt,name=spent,proto3" json:"spent,omitempty"`
+	// Multisig script unlocking the output.
+	RedeemScript []byte `protobuf:"bytes,2,opt,name=redeem_script,json=redeemScript,proto3" json:"redeem_script,omitempty"`
+	// Signatures by different keys of redeem_script.
+	Signatures []*PartialSignature `protobuf:"bytes,3,rep,name=signatures,proto3" json:"signatures,omitempty"`
+}
+
+func (x *PartialInput) Reset() {
+	*x = PartialInput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_model_psbt_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartialInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartialInput) ProtoMessage() {}
+
+func (x *PartialInput) ProtoReflect() protoreflect.Message {
+	mi := &file_model_psbt_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartialInput.ProtoReflect.Descriptor instead.
+func (*PartialInput) Descriptor() ([]byte, []int) {
+	return file_model_psbt_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PartialInput) GetSpent() *Output {
+	if x != nil {
+		return x.Spent
+	}
+	return nil
+}
+
+func (x *PartialInput) GetRedeemScript() []byte {
+	if x != nil {
+		return x.RedeemScript
+	}
+	return nil
+}
+
+func (x *PartialInput) GetSignatures() []*PartialSignature {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+// A transaction spending multisig outputs, passed between co-signers as a file until it
+// has enough signatures to be sent.
+type PartiallySignedTransaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The transaction without unlocking scripts.
+	Tx *Transaction `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	// One for each input of tx, in the same order.
+	Inputs []*PartialInput `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
+}
+
+func (x *PartiallySignedTransaction) Reset() {
+	*x = PartiallySignedTransaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_model_psbt_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartiallySignedTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartiallySignedTransaction) ProtoMessage() {}
+
+func (x *PartiallySignedTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_model_psbt_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartiallySignedTransaction.ProtoReflect.Descriptor instead.
+func (*PartiallySignedTransaction) Descriptor() ([]byte, []int) {
+	return file_model_psbt_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PartiallySignedTransaction) GetTx() *Transaction {
+	if x != nil {
+		return x.Tx
+	}
+	return nil
+}
+
+func (x *PartiallySignedTransaction) GetInputs() []*PartialInput {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+var File_model_psbt_proto protoreflect.FileDescriptor
+
+var file_model_psbt_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2f, 0x70, 0x73, 0x62, 0x74, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x17, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x4f, 0x0a, 0x10, 0x50,
+	0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x85, 0x01, 0x0a,
+	0x0c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x1d, 0x0a,
+	0x05, 0x73, 0x70, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x05, 0x73, 0x70, 0x65, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d,
+	0x72, 0x65, 0x64, 0x65, 0x65, 0x6d, 0x5f, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x65, 0x64, 0x65, 0x65, 0x6d, 0x53, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x12, 0x31, 0x0a, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x73, 0x22, 0x61, 0x0a, 0x1a, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x6c,
+	0x79, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x02, 0x74, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x02, 0x74, 0x78,
+	0x12, 0x25, 0x0a, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0d, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52,
+	0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4c, 0x75, 0x69, 0x73, 0x6d, 0x6f, 0x72, 0x6c, 0x61, 0x6e,
+	0x2f, 0x62, 0x74, 0x63, 0x5f, 0x69, 0x6e, 0x5f, 0x67, 0x6f, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_model_psbt_proto_rawDescOnce sync.Once
+	file_model_psbt_proto_rawDescData = file_model_psbt_proto_rawDesc
+)
+
+func file_model_psbt_proto_rawDescGZIP() []byte {
+	file_model_psbt_proto_rawDescOnce.Do(func() {
+		file_model_psbt_proto_rawDescData = protoimpl.X.CompressGZIP(file_model_psbt_proto_rawDescData)
+	})
+	return file_model_psbt_proto_rawDescData
+}
+
+var file_model_psbt_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_model_psbt_proto_goTypes = []interface{}{
+	(*PartialSignature)(nil),           // 0: PartialSignature
+	(*PartialInput)(nil),               // 1: PartialInput
+	(*PartiallySignedTransaction)(nil), // 2: PartiallySignedTransaction
+	(*Output)(nil),                     // 3: Output
+	(*Transaction)(nil),                // 4: Transaction
+}
+var file_model_psbt_proto_depIdxs = []int32{
+	3, // 0: PartialInput.spent:type_name -> Output
+	0, // 1: PartialInput.signatures:type_name -> PartialSignature
+	4, // 2: PartiallySignedTransaction.tx:type_name -> Transaction
+	1, // 3: PartiallySignedTransaction.inputs:type_name -> PartialInput
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_model_psbt_proto_init() }
+func file_model_psbt_proto_init() {
+	if File_model_psbt_proto != nil {
+		return
+	}
+	file_model_transaction_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_model_psbt_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PartialSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_model_psbt_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PartialInput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_model_psbt_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PartiallySignedTransaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_model_psbt_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_model_psbt_proto_goTypes,
+		DependencyIndexes: file_model_psbt_proto_depIdxs,
+		MessageInfos:      file_model_psbt_proto_msgTypes,
+	}.Build()
+	File_model_psbt_proto = out.File
+	file_model_psbt_proto_rawDesc = nil
+	file_model_psbt_proto_goTypes = nil
+	file_model_psbt_proto_depIdxs = nil
+}
@@ -0,0 +1,354 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/Luismorlan/btc_in_go/address"
+)
+
+// Maximum size in bytes of the number taken by OP_CHECKLOCKTIMEVERIFY and
+// OP_CHECKSEQUENCEVERIFY, which is larger than MAX_NUM_SIZE so that times after 2038 fit.
+const LOCK_TIME_NUM_SIZE = 5
+
+// Checker checks what a script can't know by itself, i.e. the transaction spending the
+// output and the signatures over it.
+type Checker interface {
+	// Whether sig, which ends with its signature hash type, is a valid signature of the
+	// transaction by the encoded public key pk.
+	CheckSig(sig []byte, pk []byte) bool
+	// Whether the transaction is locked until at least lockTime, which is non-negative.
+	CheckLockTime(lockTime int64) bool
+	// Whether the input is relatively locked for at least sequence, which is non-negative.
+	CheckSequence(sequence int64) bool
+}
+
+type stack [][]byte
+
+func (s *stack) push(b []byte) {
+	*s = append(*s, b)
+}
+
+func (s *stack) pop() ([]byte, error) {
+	if len(*s) == 0 {
+		return nil, errors.New("stack is empty")
+	}
+	top := (*s)[len(*s)-1]
+	*s = (*s)[:len(*s)-1]
+	return top, nil
+}
+
+func (s *stack) peek() ([]byte, error) {
+	if len(*s) == 0 {
+		return nil, errors.New("stack is empty")
+	}
+	return (*s)[len(*s)-1], nil
+}
+
+func (s *stack) popNum(maxSize int) (int64, error) {
+	b, err := s.pop()
+	if err != nil {
+		return 0, err
+	}
+	return DecodeNum(b, maxSize)
+}
+
+func (s *stack) popBool() (bool, error) {
+	b, err := s.pop()
+	if err != nil {
+		return false, err
+	}
+	return castToBool(b), nil
+}
+
+// An element is false if all its bytes are 0, except the sign bit of the last byte.
+func castToBool(b []byte) bool {
+	for i, v := range b {
+		if v != 0 && !(i == len(b)-1 && v == 0x80) {
+			return true
+		}
+	}
+	return false
+}
+
+func boolToElement(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+	return []byte{}
+}
+
+// Verify that the unlocking script of an input unlocks the locking script of the output
+// it spends. The unlocking script can only push data, which is the initial stack of the
+// locking script. The locking script succeeds if it ends with true on top of the stack.
+// If the locking script was created by PayToScriptHash, the redeem script pushed last by
+// the unlocking script must then succeed on the rest of the initial stack as well.
+func Verify(unlocking []byte, locking []byte, checker Checker) error {
+	if len(locking) == 0 {
+		return errors.New("locking script is empty")
+	}
+	if !IsPushOnly(unlocking) {
+		return errors.New("unlocking script can only push data")
+	}
+	s := stack{}
+	if err := execute(unlocking, &s, checker); err != nil {
+		return fmt.Errorf("unlocking script: %s", err)
+	}
+	_, isScriptHash := ExtractScriptHash(locking)
+	initial := append(stack{}, s...)
+	if err := execute(locking, &s, checker); err != nil {
+		return fmt.Errorf("locking script: %s", err)
+	}
+	if top, err := s.peek(); err != nil || !castToBool(top) {
+		return errors.New("script evaluated to false")
+	}
+	if !isScriptHash {
+		return nil
+	}
+
+	// The locking script checked the hash of the redeem script on top of the stack.
+	s = initial
+	redeem, err := s.pop()
+	if err != nil {
+		return err
+	}
+	if err := execute(redeem, &s, checker); err != nil {
+		return fmt.Errorf("redeem script: %s", err)
+	}
+	if top, err := s.peek(); err != nil || !castToBool(top) {
+		return errors.New("redeem script evaluated to false")
+	}
+	return nil
+}
+
+// Execute the script on the stack.
+func execute(script []byte, s *stack, checker Checker) error {
+	if len(script) > MAX_SCRIPT_SIZE {
+		return fmt.Errorf("script of %d bytes is larger than %d bytes", len(script), MAX_SCRIPT_SIZE)
+	}
+	insts, err := Parse(script)
+	if err != nil {
+		return err
+	}
+	ops := 0
+	// Whether each enclosing OP_IF or OP_NOTIF runs its current branch.
+	conds := []bool{}
+	for _, inst := range insts {
+		if !inst.IsPush() {
+			ops++
+		}
+		if ops > MAX_OPS {
+			return fmt.Errorf("script has more than %d opcodes", MAX_OPS)
+		}
+		running := isRunning(conds)
+		switch {
+		case inst.Op == OP_IF || inst.Op == OP_NOTIF:
+			cond := false
+			if running {
+				if cond, err = s.popBool(); err != nil {
+					return err
+				}
+				cond = cond == (inst.Op == OP_IF)
+			}
+			conds = append(conds, cond)
+		case inst.Op == OP_ELSE:
+			if len(conds) == 0 {
+				return errors.New("OP_ELSE without OP_IF")
+			}
+			conds[len(conds)-1] = !conds[len(conds)-1]
+		case inst.Op == OP_ENDIF:
+			if len(conds) == 0 {
+				return errors.New("OP_ENDIF without OP_IF")
+			}
+			conds = conds[:len(conds)-1]
+		case running:
+			if err := step(inst, s, checker, &ops); err != nil {
+				return err
+			}
+		}
+		if len(*s) > MAX_STACK_SIZE {
+			return fmt.Errorf("stack has more than %d elements", MAX_STACK_SIZE)
+		}
+	}
+	if len(conds) > 0 {
+		return errors.New("OP_IF without OP_ENDIF")
+	}
+	return nil
+}
+
+// Whether opcodes run, i.e. every enclosing branch runs.
+func isRunning(conds []bool) bool {
+	for _, cond := range conds {
+		if !cond {
+			return false
+		}
+	}
+	return true
+}
+
+// Execute a single instruction. ops counts opcodes toward MAX_OPS.
+func step(inst Instruction, s *stack, checker Checker, ops *int) error {
+	if inst.IsPush() {
+		data := smallInt(inst)
+		if len(data) > MAX_ELEMENT_SIZE {
+			return fmt.Errorf("pushed element of %d bytes is larger than %d bytes", len(data), MAX_ELEMENT_SIZE)
+		}
+		s.push(data)
+		return nil
+	}
+
+	switch inst.Op {
+	case OP_VERIFY:
+		ok, err := s.popBool()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("OP_VERIFY failed")
+		}
+	case OP_RETURN:
+		return errors.New("OP_RETURN is unspendable")
+	case OP_DROP:
+		if _, err := s.pop(); err != nil {
+			return err
+		}
+	case OP_DUP:
+		top, err := s.peek()
+		if err != nil {
+			return err
+		}
+		s.push(top)
+	case OP_SIZE:
+		top, err := s.peek()
+		if err != nil {
+			return err
+		}
+		s.push(EncodeNum(int64(len(top))))
+	case OP_EQUAL, OP_EQUALVERIFY:
+		a, err := s.pop()
+		if err != nil {
+			return err
+		}
+		b, err := s.pop()
+		if err != nil {
+			return err
+		}
+		if inst.Op == OP_EQUALVERIFY {
+			if !bytes.Equal(a, b) {
+				return errors.New("OP_EQUALVERIFY failed")
+			}
+			return nil
+		}
+		s.push(boolToElement(bytes.Equal(a, b)))
+	case OP_SHA256, OP_HASH160:
+		top, err := s.pop()
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(top)
+		if inst.Op == OP_HASH160 {
+			s.push(hash[:address.HASH_SIZE])
+		} else {
+			s.push(hash[:])
+		}
+	case OP_CHECKSIG, OP_CHECKSIGVERIFY:
+		pk, err := s.pop()
+		if err != nil {
+			return err
+		}
+		sig, err := s.pop()
+		if err != nil {
+			return err
+		}
+		ok := checker.CheckSig(sig, pk)
+		if inst.Op == OP_CHECKSIGVERIFY {
+			if !ok {
+				return errors.New("OP_CHECKSIGVERIFY failed")
+			}
+			return nil
+		}
+		s.push(boolToElement(ok))
+	case OP_CHECKMULTISIG, OP_CHECKMULTISIGVERIFY:
+		ok, err := checkMultiSig(s, checker, ops)
+		if err != nil {
+			return err
+		}
+		if inst.Op == OP_CHECKMULTISIGVERIFY {
+			if !ok {
+				return errors.New("OP_CHECKMULTISIGVERIFY failed")
+			}
+			return nil
+		}
+		s.push(boolToElement(ok))
+	case OP_CHECKLOCKTIMEVERIFY, OP_CHECKSEQUENCEVERIFY:
+		top, err := s.peek()
+		if err != nil {
+			return err
+		}
+		lock, err := DecodeNum(top, LOCK_TIME_NUM_SIZE)
+		if err != nil {
+			return err
+		}
+		if lock < 0 {
+			return fmt.Errorf("negative lock: %d", lock)
+		}
+		if inst.Op == OP_CHECKLOCKTIMEVERIFY && !checker.CheckLockTime(lock) {
+			return fmt.Errorf("transaction is not locked until %d", lock)
+		}
+		if inst.Op == OP_CHECKSEQUENCEVERIFY && !checker.CheckSequence(lock) {
+			return fmt.Errorf("input is not relatively locked for %d", lock)
+		}
+	default:
+		return fmt.Errorf("unknown opcode: %#x", inst.Op)
+	}
+	return nil
+}
+
+// Pop the operands of OP_CHECKMULTISIG and return whether the signatures are valid. Each
+// signature must match a later key than the previous one, so keys are tried at most once.
+func checkMultiSig(s *stack, checker Checker, ops *int) (bool, error) {
+	n, err := s.popNum(MAX_NUM_SIZE)
+	if err != nil {
+		return false, err
+	}
+	if n < 0 || n > MAX_MULTISIG_KEYS {
+		return false, fmt.Errorf("number of public keys %d is not between 0 and %d", n, MAX_MULTISIG_KEYS)
+	}
+	*ops += int(n)
+	if *ops > MAX_OPS {
+		return false, fmt.Errorf("script has more than %d opcodes", MAX_OPS)
+	}
+	pks := make([][]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		if pks[i], err = s.pop(); err != nil {
+			return false, err
+		}
+	}
+	m, err := s.popNum(MAX_NUM_SIZE)
+	if err != nil {
+		return false, err
+	}
+	if m < 0 || m > n {
+		return false, fmt.Errorf("number of signatures %d is not between 0 and %d", m, n)
+	}
+	sigs := make([][]byte, m)
+	for i := m - 1; i >= 0; i-- {
+		if sigs[i], err = s.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	k := 0
+	for _, sig := range sigs {
+		for k < len(pks) && !checker.CheckSig(sig, pks[k]) {
+			k++
+		}
+		if k == len(pks) {
+			return false, nil
+		}
+		k++
+	}
+	return true, nil
+}
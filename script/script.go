@@ -0,0 +1,345 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/Luismorlan/btc_in_go/address"
+)
+
+// Opcodes of the script language. A script is a sequence of opcodes, each push opcode is
+// followed by the data it pushes.
+const (
+	// Push an empty element, which is false and the number 0.
+	OP_0 = 0x00
+	// Opcodes from 0x01 to 0x4b push the next that many bytes.
+	OP_DATA_75 = 0x4b
+	// Push the next N bytes, where N is the next 1 byte.
+	OP_PUSHDATA1 = 0x4c
+	// Push the next N bytes, where N is the next 2 bytes in little endian.
+	OP_PUSHDATA2 = 0x4d
+	// Opcodes from OP_1 to OP_16 push the number 1 to 16.
+	OP_1  = 0x51
+	OP_16 = 0x60
+	// Run the following opcodes up to OP_ELSE or OP_ENDIF if the top element is true,
+	// otherwise those after OP_ELSE if any. The element is removed.
+	OP_IF = 0x63
+	// Like OP_IF, but runs the first branch if the top element is false.
+	OP_NOTIF = 0x64
+	// Switch to the other branch of the innermost OP_IF or OP_NOTIF.
+	OP_ELSE = 0x67
+	// End the innermost OP_IF or OP_NOTIF.
+	OP_ENDIF = 0x68
+	// Fail unless the top element is true, which is removed.
+	OP_VERIFY = 0x69
+	// Always fail, which makes an output unspendable.
+	OP_RETURN = 0x6a
+	// Remove the top element.
+	OP_DROP = 0x75
+	// Duplicate the top element.
+	OP_DUP = 0x76
+	// Push the size in bytes of the top element, which is kept.
+	OP_SIZE = 0x82
+	// Replace the top 2 elements with whether they are equal.
+	OP_EQUAL = 0x87
+	// OP_EQUAL followed by OP_VERIFY.
+	OP_EQUALVERIFY = 0x88
+	// Replace the top element with its SHA256.
+	OP_SHA256 = 0xa8
+	// Replace the top element with its public key hash, the first 20 bytes of its SHA256.
+	OP_HASH160 = 0xa9
+	// Replace a signature and the public key on top of it with whether the signature is
+	// valid for the transaction.
+	OP_CHECKSIG = 0xac
+	// OP_CHECKSIG followed by OP_VERIFY.
+	OP_CHECKSIGVERIFY = 0xad
+	// Replace M signatures, the number M, N public keys and the number N with whether each
+	// signature is valid for a different one of the keys, in the same order.
+	OP_CHECKMULTISIG = 0xae
+	// OP_CHECKMULTISIG followed by OP_VERIFY.
+	OP_CHECKMULTISIGVERIFY = 0xaf
+	// Fail unless the transaction is locked until at least the top element, a height or a
+	// time like the lock time of transactions. The element is kept, usually dropped next.
+	OP_CHECKLOCKTIMEVERIFY = 0xb1
+	// Fail unless the input is relatively locked for at least the top element, a number of
+	// blocks or a time like the sequence of inputs. The element is kept, usually dropped next.
+	OP_CHECKSEQUENCEVERIFY = 0xb2
+)
+
+// Resource limits, a script exceeding any of them fails.
+const (
+	// Maximum size of a script in bytes.
+	MAX_SCRIPT_SIZE = 10000
+	// Maximum size of a pushed element in bytes, enough for RSA keys of 4096 bits.
+	MAX_ELEMENT_SIZE = 1024
+	// Maximum number of non-push opcodes in a script, each key of OP_CHECKMULTISIG counts
+	// as one as well.
+	MAX_OPS = 201
+	// Maximum number of elements on the stack.
+	MAX_STACK_SIZE = 1000
+	// Maximum number of public keys of OP_CHECKMULTISIG.
+	MAX_MULTISIG_KEYS = 20
+	// Maximum size in bytes of numbers taken by opcodes. Lock times take 5 bytes.
+	MAX_NUM_SIZE = 4
+)
+
+// An opcode and the data it pushes, if any.
+type Instruction struct {
+	Op   byte
+	Data []byte
+}
+
+// Whether the instruction pushes data or a number onto the stack.
+func (i Instruction) IsPush() bool {
+	return i.Op <= OP_PUSHDATA2 || (i.Op >= OP_1 && i.Op <= OP_16)
+}
+
+// Split a script into instructions.
+func Parse(s []byte) ([]Instruction, error) {
+	res := []Instruction{}
+	for i := 0; i < len(s); {
+		op := s[i]
+		i++
+		size := 0
+		switch {
+		case op <= OP_DATA_75:
+			size = int(op)
+		case op == OP_PUSHDATA1:
+			if i+1 > len(s) {
+				return nil, errors.New("script ends in the length of OP_PUSHDATA1")
+			}
+			size = int(s[i])
+			i++
+		case op == OP_PUSHDATA2:
+			if i+2 > len(s) {
+				return nil, errors.New("script ends in the length of OP_PUSHDATA2")
+			}
+			size = int(binary.LittleEndian.Uint16(s[i:]))
+			i += 2
+		}
+		if i+size > len(s) {
+			return nil, fmt.Errorf("script ends in %d bytes of pushed data", size)
+		}
+		inst := Instruction{Op: op}
+		if op <= OP_PUSHDATA2 {
+			inst.Data = s[i : i+size]
+		}
+		res = append(res, inst)
+		i += size
+	}
+	return res, nil
+}
+
+// Whether the script only pushes data, which is required for unlocking scripts.
+func IsPushOnly(s []byte) bool {
+	insts, err := Parse(s)
+	if err != nil {
+		return false
+	}
+	for _, inst := range insts {
+		if !inst.IsPush() {
+			return false
+		}
+	}
+	return true
+}
+
+// Append an instruction pushing data to the script, with the shortest push opcode.
+func AppendData(s []byte, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n <= OP_DATA_75:
+		s = append(s, byte(n))
+	case n <= 0xff:
+		s = append(s, OP_PUSHDATA1, byte(n))
+	default:
+		s = append(s, OP_PUSHDATA2, byte(n), byte(n>>8))
+	}
+	return append(s, data...)
+}
+
+// Append an instruction pushing the number to the script, OP_0 to OP_16 for small numbers.
+func AppendInt(s []byte, n int64) []byte {
+	if n == 0 {
+		return append(s, OP_0)
+	}
+	if n >= 1 && n <= 16 {
+		return append(s, byte(OP_1+n-1))
+	}
+	return AppendData(s, EncodeNum(n))
+}
+
+// Encode a number as a stack element, in little endian with the highest bit as sign.
+// 0 is the empty element.
+func EncodeNum(n int64) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+	negative := n < 0
+	abs := uint64(n)
+	if negative {
+		abs = uint64(-n)
+	}
+	res := []byte{}
+	for abs > 0 {
+		res = append(res, byte(abs))
+		abs >>= 8
+	}
+	if res[len(res)-1]&0x80 != 0 {
+		extra := byte(0x00)
+		if negative {
+			extra = 0x80
+		}
+		res = append(res, extra)
+	} else if negative {
+		res[len(res)-1] |= 0x80
+	}
+	return res
+}
+
+// Decode a number of at most maxSize bytes encoded by EncodeNum. Only the shortest
+// encoding is accepted, so every number has one encoding.
+func DecodeNum(b []byte, maxSize int) (int64, error) {
+	if len(b) > maxSize {
+		return 0, fmt.Errorf("number of %d bytes is longer than %d bytes", len(b), maxSize)
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	last := b[len(b)-1]
+	if last&0x7f == 0 && (len(b) == 1 || b[len(b)-2]&0x80 == 0) {
+		return 0, errors.New("number is not encoded in the shortest form")
+	}
+	var n int64
+	for i := len(b) - 1; i >= 0; i-- {
+		n = n<<8 | int64(b[i])
+	}
+	if last&0x80 != 0 {
+		n &^= int64(0x80) << (8 * (len(b) - 1))
+		n = -n
+	}
+	return n, nil
+}
+
+// Return a locking script paying to the hash of a public key. The unlocking script pushes
+// a signature and the public key.
+func PayToPublicKeyHash(hash []byte) []byte {
+	s := []byte{OP_DUP, OP_HASH160}
+	s = AppendData(s, hash)
+	return append(s, OP_EQUALVERIFY, OP_CHECKSIG)
+}
+
+// Return a locking script paying to a public key. The unlocking script pushes a signature.
+func PayToPublicKey(pk []byte) []byte {
+	return append(AppendData(nil, pk), OP_CHECKSIG)
+}
+
+// Return the public key hash if the script was created by PayToPublicKeyHash.
+func ExtractPublicKeyHash(s []byte) ([]byte, bool) {
+	insts, err := Parse(s)
+	if err != nil || len(insts) != 5 {
+		return nil, false
+	}
+	hash := insts[2].Data
+	if len(hash) != address.HASH_SIZE || !bytes.Equal(s, PayToPublicKeyHash(hash)) {
+		return nil, false
+	}
+	return hash, true
+}
+
+// Return a locking script paying to the hash of a redeem script. The unlocking script
+// pushes what unlocks the redeem script, and then the redeem script itself.
+func PayToScriptHash(hash []byte) []byte {
+	s := []byte{OP_HASH160}
+	s = AppendData(s, hash)
+	return append(s, OP_EQUAL)
+}
+
+// Return the redeem script hash if the script was created by PayToScriptHash.
+func ExtractScriptHash(s []byte) ([]byte, bool) {
+	insts, err := Parse(s)
+	if err != nil || len(insts) != 3 {
+		return nil, false
+	}
+	hash := insts[1].Data
+	if len(hash) != address.HASH_SIZE || !bytes.Equal(s, PayToScriptHash(hash)) {
+		return nil, false
+	}
+	return hash, true
+}
+
+// Return a script requiring m signatures by different ones of the public keys, in the
+// same order as the keys. The unlocking script pushes the signatures.
+func MultiSig(m int, pks [][]byte) []byte {
+	s := AppendInt(nil, int64(m))
+	for _, pk := range pks {
+		s = AppendData(s, pk)
+	}
+	s = AppendInt(s, int64(len(pks)))
+	return append(s, OP_CHECKMULTISIG)
+}
+
+// Return the number of signatures required and the public keys if the script was created
+// by MultiSig.
+func ExtractMultiSig(s []byte) (int, [][]byte, bool) {
+	insts, err := Parse(s)
+	if err != nil || len(insts) < 3 {
+		return 0, nil, false
+	}
+	m, err := DecodeNum(smallInt(insts[0]), MAX_NUM_SIZE)
+	if err != nil {
+		return 0, nil, false
+	}
+	pks := [][]byte{}
+	for _, inst := range insts[1 : len(insts)-2] {
+		pks = append(pks, inst.Data)
+	}
+	if m < 1 || int(m) > len(pks) || len(pks) > MAX_MULTISIG_KEYS || !bytes.Equal(s, MultiSig(int(m), pks)) {
+		return 0, nil, false
+	}
+	return int(m), pks, true
+}
+
+// Size in bytes of the secret whose SHA256 a hash time lock requires.
+const PREIMAGE_SIZE = 32
+
+// Return a hash time locked script. The recipient can spend it by revealing a secret of
+// PREIMAGE_SIZE bytes whose SHA256 is hash, the refund key any time from lockTime on. Keys
+// are identified by public key hashes. The unlocking script pushes a signature and the
+// public key, followed by the secret and 1 to claim, or 0 to refund.
+func HashTimeLock(hash []byte, recipient []byte, lockTime int64, refund []byte) []byte {
+	s := []byte{OP_IF, OP_SIZE}
+	s = append(AppendInt(s, PREIMAGE_SIZE), OP_EQUALVERIFY, OP_SHA256)
+	s = append(AppendData(s, hash), OP_EQUALVERIFY, OP_DUP, OP_HASH160)
+	s = append(AppendData(s, recipient), OP_ELSE)
+	s = append(AppendInt(s, lockTime), OP_CHECKLOCKTIMEVERIFY, OP_DROP, OP_DUP, OP_HASH160)
+	s = append(AppendData(s, refund), OP_ENDIF)
+	return append(s, OP_EQUALVERIFY, OP_CHECKSIG)
+}
+
+// Return the hash, the recipient, the lock time and the refund key if the script was
+// created by HashTimeLock.
+func ExtractHashTimeLock(s []byte) ([]byte, []byte, int64, []byte, bool) {
+	insts, err := Parse(s)
+	if err != nil || len(insts) != 20 {
+		return nil, nil, 0, nil, false
+	}
+	hash, recipient, refund := insts[5].Data, insts[9].Data, insts[16].Data
+	lockTime, err := DecodeNum(smallInt(insts[11]), LOCK_TIME_NUM_SIZE)
+	if err != nil || len(hash) != sha256.Size || len(recipient) != address.HASH_SIZE || len(refund) != address.HASH_SIZE ||
+		!bytes.Equal(s, HashTimeLock(hash, recipient, lockTime, refund)) {
+		return nil, nil, 0, nil, false
+	}
+	return hash, recipient, lockTime, refund, true
+}
+
+// Return the number pushed by OP_1 to OP_16, or the data pushed otherwise.
+func smallInt(inst Instruction) []byte {
+	if inst.Op >= OP_1 && inst.Op <= OP_16 {
+		return EncodeNum(int64(inst.Op-OP_1) + 1)
+	}
+	return inst.Data
+}
@@ -0,0 +1,244 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A signature is valid if it equals "sig" followed by its public key.
+type testChecker struct {
+	lockTime int64
+	sequence int64
+}
+
+func (c testChecker) CheckSig(sig []byte, pk []byte) bool {
+	return bytes.Equal(sig, append([]byte("sig"), pk...))
+}
+
+func (c testChecker) CheckLockTime(lockTime int64) bool {
+	return lockTime <= c.lockTime
+}
+
+func (c testChecker) CheckSequence(sequence int64) bool {
+	return sequence <= c.sequence
+}
+
+func testSig(pk string) []byte {
+	return []byte("sig" + pk)
+}
+
+func TestNum(t *testing.T) {
+	for _, n := range []int64{0, 1, -1, 127, 128, -128, 255, 256, 32767, -32768, 1 << 31, 1<<32 - 1} {
+		b := EncodeNum(n)
+		m, err := DecodeNum(b, 5)
+		assert.Nil(t, err)
+		assert.Equal(t, n, m)
+	}
+	assert.Equal(t, []byte{0x80, 0x00}, EncodeNum(128))
+	assert.Equal(t, []byte{0x81}, EncodeNum(-1))
+	// Numbers must be encoded in the shortest form and fit in the size.
+	_, err := DecodeNum([]byte{0x01, 0x00}, 5)
+	assert.NotNil(t, err)
+	_, err = DecodeNum([]byte{0x80}, 5)
+	assert.NotNil(t, err)
+	_, err = DecodeNum(EncodeNum(1<<32), 4)
+	assert.NotNil(t, err)
+}
+
+func TestParse(t *testing.T) {
+	data := bytes.Repeat([]byte{7}, 300)
+	s := AppendData(AppendInt(AppendData(nil, []byte{1, 2}), 3), data)
+	s = append(s, OP_DUP)
+	insts, err := Parse(s)
+	assert.Nil(t, err)
+	assert.Equal(t, []Instruction{
+		{Op: 2, Data: []byte{1, 2}},
+		{Op: OP_1 + 2},
+		{Op: OP_PUSHDATA2, Data: data},
+		{Op: OP_DUP},
+	}, insts)
+	assert.False(t, IsPushOnly(s))
+	assert.True(t, IsPushOnly(s[:len(s)-1]))
+
+	// Pushes running past the end are rejected.
+	_, err = Parse(s[:len(s)-2])
+	assert.NotNil(t, err)
+	_, err = Parse([]byte{OP_PUSHDATA1})
+	assert.NotNil(t, err)
+}
+
+func TestPayToPublicKeyHash(t *testing.T) {
+	pk := []byte("alice")
+	hash := sha256.Sum256(pk)
+	locking := PayToPublicKeyHash(hash[:20])
+	extracted, ok := ExtractPublicKeyHash(locking)
+	assert.True(t, ok)
+	assert.Equal(t, hash[:20], extracted)
+	_, ok = ExtractPublicKeyHash(PayToPublicKey(pk))
+	assert.False(t, ok)
+
+	unlocking := AppendData(AppendData(nil, testSig("alice")), pk)
+	assert.Nil(t, Verify(unlocking, locking, testChecker{}))
+	// Another key with its valid signature doesn't match the hash.
+	unlocking = AppendData(AppendData(nil, testSig("bob")), []byte("bob"))
+	assert.NotNil(t, Verify(unlocking, locking, testChecker{}))
+	// A wrong signature leaves false on the stack.
+	unlocking = AppendData(AppendData(nil, testSig("bob")), pk)
+	assert.NotNil(t, Verify(unlocking, locking, testChecker{}))
+	// Unlocking scripts can only push data.
+	unlocking = append(AppendData(nil, testSig("alice")), OP_DUP)
+	assert.NotNil(t, Verify(unlocking, PayToPublicKey(pk), testChecker{}))
+	// Nobody can spend an empty locking script.
+	assert.NotNil(t, Verify(AppendInt(nil, 1), nil, testChecker{}))
+}
+
+func TestCheckMultiSig(t *testing.T) {
+	locking := AppendInt(nil, 2)
+	for _, pk := range []string{"a", "b", "c"} {
+		locking = AppendData(locking, []byte(pk))
+	}
+	locking = append(AppendInt(locking, 3), OP_CHECKMULTISIG)
+
+	spend := func(sigs ...string) error {
+		unlocking := []byte{}
+		for _, sig := range sigs {
+			unlocking = AppendData(unlocking, testSig(sig))
+		}
+		return Verify(unlocking, locking, testChecker{})
+	}
+	assert.Nil(t, spend("a", "b"))
+	assert.Nil(t, spend("a", "c"))
+	assert.Nil(t, spend("b", "c"))
+	// Signatures must be in the order of the keys, and each key signs once.
+	assert.NotNil(t, spend("c", "a"))
+	assert.NotNil(t, spend("a", "a"))
+	assert.NotNil(t, spend("a"))
+	assert.NotNil(t, spend("a", "d"))
+}
+
+func TestCheckLockTime(t *testing.T) {
+	locking := append(AppendInt(nil, 100), OP_CHECKLOCKTIMEVERIFY, OP_DROP)
+	locking = append(AppendData(locking, []byte("a")), OP_CHECKSIG)
+	unlocking := AppendData(nil, testSig("a"))
+	assert.Nil(t, Verify(unlocking, locking, testChecker{lockTime: 100}))
+	assert.NotNil(t, Verify(unlocking, locking, testChecker{lockTime: 99}))
+
+	negative := append(AppendInt(nil, -1), OP_CHECKLOCKTIMEVERIFY)
+	assert.NotNil(t, Verify(nil, negative, testChecker{lockTime: 100}))
+
+	relative := append(AppendInt(nil, 6), OP_CHECKSEQUENCEVERIFY)
+	assert.Nil(t, Verify(nil, relative, testChecker{sequence: 6}))
+	assert.NotNil(t, Verify(nil, relative, testChecker{sequence: 5, lockTime: 6}))
+	negative = append(AppendInt(nil, -1), OP_CHECKSEQUENCEVERIFY)
+	assert.NotNil(t, Verify(nil, negative, testChecker{sequence: 100}))
+}
+
+func TestLimits(t *testing.T) {
+	// Too many opcodes.
+	locking := AppendInt(nil, 1)
+	for i := 0; i < MAX_OPS+1; i++ {
+		locking = append(locking, OP_DUP, OP_DROP)
+	}
+	assert.NotNil(t, Verify(nil, locking, testChecker{}))
+	assert.Nil(t, Verify(nil, locking[:1+MAX_OPS], testChecker{}))
+
+	// Too large elements.
+	big := AppendData(nil, make([]byte, MAX_ELEMENT_SIZE+1))
+	assert.NotNil(t, Verify(big, []byte{OP_DROP, OP_1}, testChecker{}))
+
+	// Too many elements on the stack.
+	unlocking := []byte{}
+	for i := 0; i < MAX_STACK_SIZE+1; i++ {
+		unlocking = AppendInt(unlocking, 1)
+	}
+	assert.NotNil(t, Verify(unlocking, []byte{OP_1}, testChecker{}))
+
+	// Too large scripts.
+	assert.NotNil(t, Verify(nil, append(make([]byte, MAX_SCRIPT_SIZE), OP_1), testChecker{}))
+
+	// Unknown opcodes and OP_RETURN fail.
+	assert.NotNil(t, Verify(nil, []byte{OP_1, 0xff}, testChecker{}))
+	assert.NotNil(t, Verify(nil, []byte{OP_1, OP_RETURN}, testChecker{}))
+}
+
+func TestPayToScriptHash(t *testing.T) {
+	redeem := MultiSig(2, [][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	m, pks, ok := ExtractMultiSig(redeem)
+	assert.True(t, ok)
+	assert.Equal(t, 2, m)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, pks)
+	_, _, ok = ExtractMultiSig(PayToPublicKey([]byte("a")))
+	assert.False(t, ok)
+
+	hash := sha256.Sum256(redeem)
+	locking := PayToScriptHash(hash[:20])
+	extracted, ok := ExtractScriptHash(locking)
+	assert.True(t, ok)
+	assert.Equal(t, hash[:20], extracted)
+
+	unlocking := AppendData(AppendData(AppendData(nil, testSig("a")), testSig("c")), redeem)
+	assert.Nil(t, Verify(unlocking, locking, testChecker{}))
+	// The redeem script must succeed as well as match the hash.
+	unlocking = AppendData(AppendData(AppendData(nil, testSig("a")), testSig("d")), redeem)
+	assert.NotNil(t, Verify(unlocking, locking, testChecker{}))
+	other := MultiSig(1, [][]byte{[]byte("d")})
+	unlocking = AppendData(AppendData(nil, testSig("d")), other)
+	assert.NotNil(t, Verify(unlocking, locking, testChecker{}))
+}
+
+func TestConditionals(t *testing.T) {
+	// Push 2 if the top element is true, 3 otherwise, and require it to be 2.
+	locking := append([]byte{OP_IF}, AppendInt(nil, 2)...)
+	locking = append(append(locking, OP_ELSE), AppendInt(nil, 3)...)
+	locking = append(AppendInt(append(locking, OP_ENDIF), 2), OP_EQUAL)
+	assert.Nil(t, Verify(AppendInt(nil, 1), locking, testChecker{}))
+	assert.NotNil(t, Verify(AppendInt(nil, 0), locking, testChecker{}))
+	notIf := append([]byte{OP_NOTIF}, locking[1:]...)
+	assert.Nil(t, Verify(AppendInt(nil, 0), notIf, testChecker{}))
+
+	// Branches not taken don't run, even nested ones.
+	nested := []byte{OP_0, OP_IF, OP_0, OP_IF, OP_RETURN, OP_ELSE, OP_RETURN, OP_ENDIF, OP_ENDIF, OP_1}
+	assert.Nil(t, Verify(nil, nested, testChecker{}))
+
+	// Conditionals must be balanced.
+	assert.NotNil(t, Verify(nil, []byte{OP_1, OP_IF, OP_1}, testChecker{}))
+	assert.NotNil(t, Verify(nil, []byte{OP_1, OP_ENDIF}, testChecker{}))
+	assert.NotNil(t, Verify(nil, []byte{OP_1, OP_ELSE}, testChecker{}))
+}
+
+func TestHashTimeLock(t *testing.T) {
+	secret := bytes.Repeat([]byte{7}, PREIMAGE_SIZE)
+	hash := sha256.Sum256(secret)
+	alice, bob := sha256.Sum256([]byte("alice")), sha256.Sum256([]byte("bob"))
+	locking := HashTimeLock(hash[:], alice[:20], 100, bob[:20])
+	h, recipient, lockTime, refund, ok := ExtractHashTimeLock(locking)
+	assert.True(t, ok)
+	assert.Equal(t, hash[:], h)
+	assert.Equal(t, alice[:20], recipient)
+	assert.Equal(t, int64(100), lockTime)
+	assert.Equal(t, bob[:20], refund)
+	_, _, _, _, ok = ExtractHashTimeLock(PayToPublicKeyHash(alice[:20]))
+	assert.False(t, ok)
+
+	claim := func(secret []byte) []byte {
+		return AppendInt(AppendData(AppendData(AppendData(nil, testSig("alice")), []byte("alice")), secret), 1)
+	}
+	refundWith := func(pk string) []byte {
+		return AppendInt(AppendData(AppendData(nil, testSig(pk)), []byte(pk)), 0)
+	}
+
+	// The recipient claims with the secret at any time.
+	assert.Nil(t, Verify(claim(secret), locking, testChecker{}))
+	assert.NotNil(t, Verify(claim(append(secret, 0)), locking, testChecker{}))
+	wrong := append([]byte{}, secret...)
+	wrong[0] = 8
+	assert.NotNil(t, Verify(claim(wrong), locking, testChecker{}))
+
+	// The refund key spends from the lock time on.
+	assert.NotNil(t, Verify(refundWith("bob"), locking, testChecker{lockTime: 99}))
+	assert.Nil(t, Verify(refundWith("bob"), locking, testChecker{lockTime: 100}))
+	assert.NotNil(t, Verify(refundWith("alice"), locking, testChecker{lockTime: 100}))
+}
@@ -32,6 +32,26 @@ type FullNodeServiceClient interface {
 	GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*GetPeersResponse, error)
 	// Return blocks in blockchain to help peers catching up with the system.
 	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error)
+	// Return a single block by hash from any branch, used to fetch missing ancestors of orphan blocks.
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error)
+	// Return headers on the main chain following the first block locator hash found on the
+	// main chain, used to download and validate the header chain before block bodies.
+	GetHeaders(ctx context.Context, in *GetHeadersRequest, opts ...grpc.CallOption) (*GetHeadersResponse, error)
+	// Return blocks by hash from any branch, used to download block bodies.
+	GetBlocksByHash(ctx context.Context, in *GetBlocksByHashRequest, opts ...grpc.CallOption) (*GetBlocksByHashResponse, error)
+	// Announce hashes of new blocks and transactions. The receiver requests the ones it
+	// doesn't have with GetData from the sender.
+	Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error)
+	// Return the announced blocks and transactions requested by hash.
+	GetData(ctx context.Context, in *GetDataRequest, opts ...grpc.CallOption) (*GetDataResponse, error)
+	// Estimate the fee rate a new transaction should pay to be included in a block soon,
+	// from recent blocks and the transaction pool.
+	EstimateFee(ctx context.Context, in *EstimateFeeRequest, opts ...grpc.CallOption) (*EstimateFeeResponse, error)
+	// Return the block subsidy at tail and the total supply issued so far.
+	GetSupply(ctx context.Context, in *GetSupplyRequest, opts ...grpc.CallOption) (*GetSupplyResponse, error)
+	// Return transactions spending outputs locked by a script, so that wallets can read
+	// what their unlocking scripts reveal, e.g. the secret of a hash time locked contract.
+	GetScriptSpends(ctx context.Context, in *GetScriptSpendsRequest, opts ...grpc.CallOption) (*GetScriptSpendsResponse, error)
 }
 
 type fullNodeServiceClient struct {
@@ -96,6 +116,78 @@ func (c *fullNodeServiceClient) Sync(ctx context.Context, in *SyncRequest, opts
 	return out, nil
 }
 
+func (c *fullNodeServiceClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error) {
+	out := new(GetBlockResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fullNodeServiceClient) GetHeaders(ctx context.Context, in *GetHeadersRequest, opts ...grpc.CallOption) (*GetHeadersResponse, error) {
+	out := new(GetHeadersResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/GetHeaders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fullNodeServiceClient) GetBlocksByHash(ctx context.Context, in *GetBlocksByHashRequest, opts ...grpc.CallOption) (*GetBlocksByHashResponse, error) {
+	out := new(GetBlocksByHashResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/GetBlocksByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fullNodeServiceClient) Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error) {
+	out := new(AnnounceResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/Announce", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fullNodeServiceClient) GetData(ctx context.Context, in *GetDataRequest, opts ...grpc.CallOption) (*GetDataResponse, error) {
+	out := new(GetDataResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/GetData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fullNodeServiceClient) EstimateFee(ctx context.Context, in *EstimateFeeRequest, opts ...grpc.CallOption) (*EstimateFeeResponse, error) {
+	out := new(EstimateFeeResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/EstimateFee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fullNodeServiceClient) GetSupply(ctx context.Context, in *GetSupplyRequest, opts ...grpc.CallOption) (*GetSupplyResponse, error) {
+	out := new(GetSupplyResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/GetSupply", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fullNodeServiceClient) GetScriptSpends(ctx context.Context, in *GetScriptSpendsRequest, opts ...grpc.CallOption) (*GetScriptSpendsResponse, error) {
+	out := new(GetScriptSpendsResponse)
+	err := c.cc.Invoke(ctx, "/FullNodeService/GetScriptSpends", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FullNodeServiceServer is the server API for FullNodeService service.
 // All implementations must embed UnimplementedFullNodeServiceServer
 // for forward compatibility
@@ -114,6 +206,26 @@ type FullNodeServiceServer interface {
 	GetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error)
 	// Return blocks in blockchain to help peers catching up with the system.
 	Sync(context.Context, *SyncRequest) (*SyncResponse, error)
+	// Return a single block by hash from any branch, used to fetch missing ancestors of orphan blocks.
+	GetBlock(context.Context, *GetBlockRequest) (*GetBlockResponse, error)
+	// Return headers on the main chain following the first block locator hash found on the
+	// main chain, used to download and validate the header chain before block bodies.
+	GetHeaders(context.Context, *GetHeadersRequest) (*GetHeadersResponse, error)
+	// Return blocks by hash from any branch, used to download block bodies.
+	GetBlocksByHash(context.Context, *GetBlocksByHashRequest) (*GetBlocksByHashResponse, error)
+	// Announce hashes of new blocks and transactions. The receiver requests the ones it
+	// doesn't have with GetData from the sender.
+	Announce(context.Context, *AnnounceRequest) (*AnnounceResponse, error)
+	// Return the announced blocks and transactions requested by hash.
+	GetData(context.Context, *GetDataRequest) (*GetDataResponse, error)
+	// Estimate the fee rate a new transaction should pay to be included in a block soon,
+	// from recent blocks and the transaction pool.
+	EstimateFee(context.Context, *EstimateFeeRequest) (*EstimateFeeResponse, error)
+	// Return the block subsidy at tail and the total supply issued so far.
+	GetSupply(context.Context, *GetSupplyRequest) (*GetSupplyResponse, error)
+	// Return transactions spending outputs locked by a script, so that wallets can read
+	// what their unlocking scripts reveal, e.g. the secret of a hash time locked contract.
+	GetScriptSpends(context.Context, *GetScriptSpendsRequest) (*GetScriptSpendsResponse, error)
 	mustEmbedUnimplementedFullNodeServiceServer()
 }
 
@@ -139,6 +251,30 @@ func (UnimplementedFullNodeServiceServer) GetPeers(context.Context, *GetPeersReq
 func (UnimplementedFullNodeServiceServer) Sync(context.Context, *SyncRequest) (*SyncResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
 }
+func (UnimplementedFullNodeServiceServer) GetBlock(context.Context, *GetBlockRequest) (*GetBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedFullNodeServiceServer) GetHeaders(context.Context, *GetHeadersRequest) (*GetHeadersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeaders not implemented")
+}
+func (UnimplementedFullNodeServiceServer) GetBlocksByHash(context.Context, *GetBlocksByHashRequest) (*GetBlocksByHashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlocksByHash not implemented")
+}
+func (UnimplementedFullNodeServiceServer) Announce(context.Context, *AnnounceRequest) (*AnnounceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Announce not implemented")
+}
+func (UnimplementedFullNodeServiceServer) GetData(context.Context, *GetDataRequest) (*GetDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetData not implemented")
+}
+func (UnimplementedFullNodeServiceServer) EstimateFee(context.Context, *EstimateFeeRequest) (*EstimateFeeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateFee not implemented")
+}
+func (UnimplementedFullNodeServiceServer) GetSupply(context.Context, *GetSupplyRequest) (*GetSupplyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSupply not implemented")
+}
+func (UnimplementedFullNodeServiceServer) GetScriptSpends(context.Context, *GetScriptSpendsRequest) (*GetScriptSpendsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScriptSpends not implemented")
+}
 func (UnimplementedFullNodeServiceServer) mustEmbedUnimplementedFullNodeServiceServer() {}
 
 // UnsafeFullNodeServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -260,6 +396,150 @@ func _FullNodeService_Sync_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FullNodeService_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FullNodeService_GetHeaders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeadersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).GetHeaders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/GetHeaders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).GetHeaders(ctx, req.(*GetHeadersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FullNodeService_GetBlocksByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlocksByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).GetBlocksByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/GetBlocksByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).GetBlocksByHash(ctx, req.(*GetBlocksByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FullNodeService_Announce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnounceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).Announce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/Announce",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).Announce(ctx, req.(*AnnounceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FullNodeService_GetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).GetData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/GetData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).GetData(ctx, req.(*GetDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FullNodeService_EstimateFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateFeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).EstimateFee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/EstimateFee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).EstimateFee(ctx, req.(*EstimateFeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FullNodeService_GetSupply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSupplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).GetSupply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/GetSupply",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).GetSupply(ctx, req.(*GetSupplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FullNodeService_GetScriptSpends_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScriptSpendsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FullNodeServiceServer).GetScriptSpends(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/FullNodeService/GetScriptSpends",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FullNodeServiceServer).GetScriptSpends(ctx, req.(*GetScriptSpendsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FullNodeService_ServiceDesc is the grpc.ServiceDesc for FullNodeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -291,6 +571,38 @@ var FullNodeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Sync",
 			Handler:    _FullNodeService_Sync_Handler,
 		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _FullNodeService_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetHeaders",
+			Handler:    _FullNodeService_GetHeaders_Handler,
+		},
+		{
+			MethodName: "GetBlocksByHash",
+			Handler:    _FullNodeService_GetBlocksByHash_Handler,
+		},
+		{
+			MethodName: "Announce",
+			Handler:    _FullNodeService_Announce_Handler,
+		},
+		{
+			MethodName: "GetData",
+			Handler:    _FullNodeService_GetData_Handler,
+		},
+		{
+			MethodName: "EstimateFee",
+			Handler:    _FullNodeService_EstimateFee_Handler,
+		},
+		{
+			MethodName: "GetSupply",
+			Handler:    _FullNodeService_GetSupply_Handler,
+		},
+		{
+			MethodName: "GetScriptSpends",
+			Handler:    _FullNodeService_GetScriptSpends_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "service/service.proto",
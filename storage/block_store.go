@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"github.com/Luismorlan/btc_in_go/model"
+)
+
+/*
+This package persists accepted blocks so that a full node can rebuild its blockchain
+on restart instead of syncing everything from peers again.
+*/
+
+// BlockStore is the storage backend used by a full node to persist blocks. Blocks are
+// only appended, never modified, and are always appended after their parent.
+type BlockStore interface {
+	// Put appends a block that has been accepted at the given height.
+	Put(block *model.Block, height int64) error
+	// Get returns the block with the given hash, or an error if not found.
+	Get(hash string) (*model.Block, error)
+	// Has returns true if a block with the given hash has been stored.
+	Has(hash string) bool
+	// GetHashesAtHeight returns hashes of all stored blocks at the given height, including forks.
+	GetHashesAtHeight(height int64) []string
+	// Blocks returns all stored blocks in the order they were appended, thus a block's
+	// parent always comes before the block itself.
+	Blocks() ([]*model.Block, error)
+	// Close releases all resources held by the store.
+	Close() error
+}
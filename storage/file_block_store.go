@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// File holding the serialized blocks.
+	BLOCK_FILE_NAME = "blocks.dat"
+	// File holding the fixed size index records pointing into the block file.
+	INDEX_FILE_NAME = "index.dat"
+
+	// Length of a block hash in raw bytes (SHA256).
+	hashLen = 32
+	// Each block record is prefixed by payload length and payload checksum.
+	blockHeaderLen = 8
+	// Index record: hash | height | offset | length | checksum of the previous fields.
+	indexRecordLen = hashLen + 8 + 8 + 4 + 4
+)
+
+// Location of a single block in the block file.
+type indexEntry struct {
+	hash   string
+	height int64
+	offset int64
+	length uint32
+}
+
+// FileBlockStore persists blocks in an append-only block file, together with an
+// append-only index file keyed by block hash and height.
+//
+// A block is always fully written and synced to the block file before its index
+// record is written, and every index record carries its own checksum. When opening
+// the store, index records that are torn or point beyond the block file are dropped,
+// as well as any block data that isn't referenced by the index. So a crash in the
+// middle of a write loses at most the block being written and never corrupts the index.
+type FileBlockStore struct {
+	blockFile *os.File
+	indexFile *os.File
+	// Where the next block / index record will be written.
+	blockEnd int64
+	indexEnd int64
+	// Index entries in the order they were appended.
+	entries []indexEntry
+	// A map from block hash to its position in entries.
+	byHash map[string]int
+	// A map from height to all block hashes at that height.
+	byHeight map[int64][]string
+	m        sync.RWMutex
+}
+
+// Open the block store under the given directory, create the directory and files if
+// they don't exist yet. Any partially written data from a previous crash is discarded.
+func OpenFileBlockStore(dir string) (*FileBlockStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	blockFile, err := os.OpenFile(filepath.Join(dir, BLOCK_FILE_NAME), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	indexFile, err := os.OpenFile(filepath.Join(dir, INDEX_FILE_NAME), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		blockFile.Close()
+		return nil, err
+	}
+
+	s := &FileBlockStore{
+		blockFile: blockFile,
+		indexFile: indexFile,
+		byHash:    make(map[string]int),
+		byHeight:  make(map[int64][]string),
+	}
+	if err := s.recover(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load the index into memory and truncate both files to the last consistent record.
+func (s *FileBlockStore) recover() error {
+	blockInfo, err := s.blockFile.Stat()
+	if err != nil {
+		return err
+	}
+	indexInfo, err := s.indexFile.Stat()
+	if err != nil {
+		return err
+	}
+	blockSize := blockInfo.Size()
+
+	data := make([]byte, indexInfo.Size())
+	if _, err := s.indexFile.ReadAt(data, 0); err != nil && err != io.EOF {
+		return err
+	}
+
+	for off := 0; off+indexRecordLen <= len(data); off += indexRecordLen {
+		e, ok := decodeIndexRecord(data[off : off+indexRecordLen])
+		if !ok || e.offset != s.blockEnd || e.offset+blockHeaderLen+int64(e.length) > blockSize {
+			// Torn or dangling record, everything after it is discarded.
+			break
+		}
+		s.addEntry(e)
+		s.blockEnd = e.offset + blockHeaderLen + int64(e.length)
+		s.indexEnd += indexRecordLen
+	}
+
+	// Drop anything that is not covered by a valid index record.
+	if indexInfo.Size() != s.indexEnd {
+		if err := s.indexFile.Truncate(s.indexEnd); err != nil {
+			return err
+		}
+	}
+	if blockSize != s.blockEnd {
+		if err := s.blockFile.Truncate(s.blockEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileBlockStore) addEntry(e indexEntry) {
+	s.byHash[e.hash] = len(s.entries)
+	s.byHeight[e.height] = append(s.byHeight[e.height], e.hash)
+	s.entries = append(s.entries, e)
+}
+
+func (s *FileBlockStore) Put(block *model.Block, height int64) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if _, exist := s.byHash[block.Hash]; exist {
+		return fmt.Errorf("block already stored: %s", block.Hash)
+	}
+	hashBytes, err := utils.HexToBytes(block.Hash)
+	if err != nil || len(hashBytes) != hashLen {
+		return fmt.Errorf("invalid block hash: %s", block.Hash)
+	}
+	payload, err := proto.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	// 1. Write and sync the block itself.
+	record := make([]byte, blockHeaderLen+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[blockHeaderLen:], payload)
+	if _, err := s.blockFile.WriteAt(record, s.blockEnd); err != nil {
+		return err
+	}
+	if err := s.blockFile.Sync(); err != nil {
+		return err
+	}
+
+	// 2. Only then commit the index record pointing to it.
+	e := indexEntry{
+		hash:   block.Hash,
+		height: height,
+		offset: s.blockEnd,
+		length: uint32(len(payload)),
+	}
+	if _, err := s.indexFile.WriteAt(encodeIndexRecord(hashBytes, e), s.indexEnd); err != nil {
+		return err
+	}
+	if err := s.indexFile.Sync(); err != nil {
+		return err
+	}
+
+	s.addEntry(e)
+	s.blockEnd += int64(len(record))
+	s.indexEnd += indexRecordLen
+	return nil
+}
+
+func (s *FileBlockStore) Get(hash string) (*model.Block, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	i, ok := s.byHash[hash]
+	if !ok {
+		return nil, fmt.Errorf("block not found: %s", hash)
+	}
+	return s.readBlock(s.entries[i])
+}
+
+func (s *FileBlockStore) Has(hash string) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	_, ok := s.byHash[hash]
+	return ok
+}
+
+func (s *FileBlockStore) GetHashesAtHeight(height int64) []string {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return append([]string{}, s.byHeight[height]...)
+}
+
+func (s *FileBlockStore) Blocks() ([]*model.Block, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	blocks := make([]*model.Block, 0, len(s.entries))
+	for _, e := range s.entries {
+		b, err := s.readBlock(e)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+func (s *FileBlockStore) Close() error {
+	errBlock := s.blockFile.Close()
+	errIndex := s.indexFile.Close()
+	if errBlock != nil {
+		return errBlock
+	}
+	return errIndex
+}
+
+// Read and verify a single block from the block file.
+func (s *FileBlockStore) readBlock(e indexEntry) (*model.Block, error) {
+	record := make([]byte, blockHeaderLen+int(e.length))
+	if _, err := s.blockFile.ReadAt(record, e.offset); err != nil {
+		return nil, err
+	}
+	payload := record[blockHeaderLen:]
+	if binary.BigEndian.Uint32(record[0:4]) != e.length || binary.BigEndian.Uint32(record[4:8]) != crc32.ChecksumIEEE(payload) {
+		return nil, errors.New("corrupted block record: " + e.hash)
+	}
+	block := &model.Block{}
+	if err := proto.Unmarshal(payload, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func encodeIndexRecord(hashBytes []byte, e indexEntry) []byte {
+	record := make([]byte, indexRecordLen)
+	copy(record[0:hashLen], hashBytes)
+	binary.BigEndian.PutUint64(record[hashLen:hashLen+8], uint64(e.height))
+	binary.BigEndian.PutUint64(record[hashLen+8:hashLen+16], uint64(e.offset))
+	binary.BigEndian.PutUint32(record[hashLen+16:hashLen+20], e.length)
+	binary.BigEndian.PutUint32(record[hashLen+20:], crc32.ChecksumIEEE(record[:hashLen+20]))
+	return record
+}
+
+// Return false if the record checksum doesn't match, which happens on a torn write.
+func decodeIndexRecord(record []byte) (indexEntry, bool) {
+	if binary.BigEndian.Uint32(record[hashLen+20:]) != crc32.ChecksumIEEE(record[:hashLen+20]) {
+		return indexEntry{}, false
+	}
+	return indexEntry{
+		hash:   utils.BytesToHex(record[0:hashLen]),
+		height: int64(binary.BigEndian.Uint64(record[hashLen : hashLen+8])),
+		offset: int64(binary.BigEndian.Uint64(record[hashLen+8 : hashLen+16])),
+		length: binary.BigEndian.Uint32(record[hashLen+16 : hashLen+20]),
+	}, true
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/utils"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func createTestBlock(prevHash string, nounce int64) *model.Block {
+	b := &model.Block{
+		Header: &model.BlockHeader{
+			PrevHash: prevHash,
+			Nounce:   nounce,
+		},
+		Coinbase: utils.CreateCoinbaseTx(1, utils.Ed25519PublicKey{Key: []byte{1, 2, 3}}, nounce),
+	}
+	_, b.Hash = utils.MatchDifficulty(b, 0)
+	return b
+}
+
+func TestFileBlockStoreReopen(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "block_store")
+	defer os.RemoveAll(dir)
+
+	s, err := OpenFileBlockStore(dir)
+	assert.Nil(t, err)
+	b1 := createTestBlock(model.GENESIS_HASH, 1)
+	b2 := createTestBlock(b1.Hash, 2)
+	b3 := createTestBlock(b1.Hash, 3)
+	assert.Nil(t, s.Put(b1, 1))
+	assert.Nil(t, s.Put(b2, 2))
+	assert.Nil(t, s.Put(b3, 2))
+	assert.NotNil(t, s.Put(b3, 2))
+	assert.Nil(t, s.Close())
+
+	s, err = OpenFileBlockStore(dir)
+	assert.Nil(t, err)
+	defer s.Close()
+	blocks, err := s.Blocks()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(blocks))
+	for i, b := range []*model.Block{b1, b2, b3} {
+		assert.True(t, proto.Equal(b, blocks[i]))
+	}
+	assert.Equal(t, []string{b2.Hash, b3.Hash}, s.GetHashesAtHeight(2))
+	got, err := s.Get(b3.Hash)
+	assert.Nil(t, err)
+	assert.True(t, proto.Equal(b3, got))
+	assert.True(t, s.Has(b1.Hash))
+}
+
+func TestFileBlockStoreTornWrite(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "block_store")
+	defer os.RemoveAll(dir)
+
+	s, _ := OpenFileBlockStore(dir)
+	b1 := createTestBlock(model.GENESIS_HASH, 1)
+	b2 := createTestBlock(b1.Hash, 2)
+	s.Put(b1, 1)
+	s.Put(b2, 2)
+	s.Close()
+
+	// Simulate a crash while writing the second index record and a dangling block write.
+	indexPath := filepath.Join(dir, INDEX_FILE_NAME)
+	assert.Nil(t, os.Truncate(indexPath, indexRecordLen+10))
+	f, _ := os.OpenFile(filepath.Join(dir, BLOCK_FILE_NAME), os.O_APPEND|os.O_WRONLY, 0644)
+	f.Write([]byte{0, 0, 1, 0, 42})
+	f.Close()
+
+	s, err := OpenFileBlockStore(dir)
+	assert.Nil(t, err)
+	blocks, err := s.Blocks()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(blocks))
+	assert.True(t, proto.Equal(b1, blocks[0]))
+
+	// The store is usable again after recovery.
+	assert.Nil(t, s.Put(b2, 2))
+	s.Close()
+	s, _ = OpenFileBlockStore(dir)
+	defer s.Close()
+	blocks, _ = s.Blocks()
+	assert.Equal(t, 2, len(blocks))
+}
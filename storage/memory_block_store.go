@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Luismorlan/btc_in_go/model"
+)
+
+// MemoryBlockStore keeps blocks in memory only, nothing survives a restart. This is
+// useful for testing or running a throwaway node.
+type MemoryBlockStore struct {
+	// Blocks in the order they were appended.
+	blocks []*model.Block
+	// A map from block hash to block.
+	byHash map[string]*model.Block
+	// A map from height to all block hashes at that height.
+	byHeight map[int64][]string
+	m        sync.RWMutex
+}
+
+// Create an empty in-memory block store.
+func NewMemoryBlockStore() *MemoryBlockStore {
+	return &MemoryBlockStore{
+		byHash:   make(map[string]*model.Block),
+		byHeight: make(map[int64][]string),
+	}
+}
+
+func (s *MemoryBlockStore) Put(block *model.Block, height int64) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if _, exist := s.byHash[block.Hash]; exist {
+		return fmt.Errorf("block already stored: %s", block.Hash)
+	}
+	s.blocks = append(s.blocks, block)
+	s.byHash[block.Hash] = block
+	s.byHeight[height] = append(s.byHeight[height], block.Hash)
+	return nil
+}
+
+func (s *MemoryBlockStore) Get(hash string) (*model.Block, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	b, ok := s.byHash[hash]
+	if !ok {
+		return nil, fmt.Errorf("block not found: %s", hash)
+	}
+	return b, nil
+}
+
+func (s *MemoryBlockStore) Has(hash string) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	_, ok := s.byHash[hash]
+	return ok
+}
+
+func (s *MemoryBlockStore) GetHashesAtHeight(height int64) []string {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return append([]string{}, s.byHeight[height]...)
+}
+
+func (s *MemoryBlockStore) Blocks() ([]*model.Block, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return append([]*model.Block{}, s.blocks...), nil
+}
+
+func (s *MemoryBlockStore) Close() error {
+	return nil
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Luismorlan/btc_in_go/address"
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+)
+
+// Return the hash of a public key or redeem script that outputs pay to, the first
+// address.HASH_SIZE bytes of its SHA256. This is what OP_HASH160 computes.
+func HashPublicKey(pk []byte) []byte {
+	return SHA256(pk)[:address.HASH_SIZE]
+}
+
+// Return the address of the public key, the hash of its encoding by EncodePublicKey.
+func GetAddress(pk PublicKey) string {
+	return address.Encode(address.VERSION_P2PKH, HashPublicKey(EncodePublicKey(pk)))
+}
+
+// Return the address paying to the hash of the redeem script.
+func GetScriptAddress(redeem []byte) string {
+	return address.Encode(address.VERSION_P2SH, HashPublicKey(redeem))
+}
+
+// Return an output paying value to the address.
+func NewAddressOutput(value int64, addr string) (*model.Output, error) {
+	version, hash, err := address.Decode(addr)
+	if err != nil {
+		return nil, err
+	}
+	switch version {
+	case address.VERSION_P2PKH:
+		return &model.Output{Value: value, Script: script.PayToPublicKeyHash(hash)}, nil
+	case address.VERSION_P2SH:
+		return &model.Output{Value: value, Script: script.PayToScriptHash(hash)}, nil
+	default:
+		return nil, fmt.Errorf("unknown address version: %d", version)
+	}
+}
+
+// Whether the output pays to the public key encoded by EncodePublicKey, either to the key
+// itself or to its hash.
+// READONLY:
+// * output
+func IsPaidTo(output *model.Output, pk []byte) bool {
+	locking := GetLockingScript(output)
+	return bytes.Equal(locking, script.PayToPublicKeyHash(HashPublicKey(pk))) ||
+		bytes.Equal(locking, script.PayToPublicKey(pk))
+}
+
+// Return the address the output pays to, or false if it doesn't pay to a hash.
+// READONLY:
+// * output
+func GetOutputAddress(output *model.Output) (string, bool) {
+	locking := GetLockingScript(output)
+	if hash, ok := script.ExtractPublicKeyHash(locking); ok {
+		return address.Encode(address.VERSION_P2PKH, hash), true
+	}
+	if hash, ok := script.ExtractScriptHash(locking); ok {
+		return address.Encode(address.VERSION_P2SH, hash), true
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressOutput(t *testing.T) {
+	sk := createTestKey(t)
+	output, err := NewAddressOutput(10, GetAddress(sk.Public()))
+	assert.Nil(t, err)
+	assert.Equal(t, NewOutput(10, sk.Public()), output)
+	assert.True(t, IsPaidTo(output, EncodePublicKey(sk.Public())))
+	assert.False(t, IsPaidTo(output, EncodePublicKey(createTestKey(t).Public())))
+
+	// Outputs paying to the public key itself are still recognized.
+	legacy := &model.Output{Value: 10, PublicKey: sk.Public().Bytes(), KeyType: sk.Type()}
+	assert.True(t, IsPaidTo(legacy, EncodePublicKey(sk.Public())))
+
+	_, err = NewAddressOutput(10, GetAddress(sk.Public())[1:])
+	assert.NotNil(t, err)
+}
+
+func TestSpendPublicKeyHash(t *testing.T) {
+	sk := createTestKey(t)
+	spent := NewOutput(100, sk.Public())
+	tx := &model.Transaction{
+		Inputs:  []*model.Input{{PrevTxHash: "aa", Index: 0}},
+		Outputs: []*model.Output{NewOutput(90, sk.Public())},
+	}
+	assert.Nil(t, SignInput(sk, tx, 0, spent, SIGHASH_ALL))
+	insts, err := script.Parse(tx.Inputs[0].Script)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(insts))
+	assert.Equal(t, EncodePublicKey(sk.Public()), insts[1].Data)
+	assert.Nil(t, VerifyInput(tx, 0, spent))
+
+	// A valid signature of another key doesn't spend the output.
+	other := createTestKey(t)
+	assert.NotNil(t, SignInput(other, tx, 0, spent, SIGHASH_ALL))
+	sig, err := CreateSignature(other, tx, 0, spent, SIGHASH_ALL)
+	assert.Nil(t, err)
+	tx.Inputs[0].Script = script.AppendData(script.AppendData(nil, sig), EncodePublicKey(other.Public()))
+	assert.NotNil(t, VerifyInput(tx, 0, spent))
+
+	// The public key is revealed only when the output pays to its hash.
+	legacy := &model.Output{Value: 100, PublicKey: sk.Public().Bytes(), KeyType: sk.Type()}
+	assert.Nil(t, SignInput(sk, tx, 0, legacy, SIGHASH_ALL))
+	insts, err = script.Parse(tx.Inputs[0].Script)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(insts))
+	assert.Nil(t, VerifyInput(tx, 0, legacy))
+
+	// Inputs written before unlocking scripts existed only have a signature.
+	tx.Inputs[0].Signature = insts[0].Data
+	tx.Inputs[0].Script = nil
+	assert.Nil(t, VerifyInput(tx, 0, legacy))
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+All amounts are int64 in base units, floating point numbers are only for human display.
+*/
+
+// Number of base units in one coin.
+const COIN int64 = 100000000
+
+// Number of decimals when displaying an amount in coins.
+const AMOUNT_DECIMALS = 8
+
+// No single amount, nor any sum of amounts, is allowed to exceed this value. It is far
+// below the int64 limit, so adding two valid amounts never overflows.
+const MAX_MONEY int64 = 21000000 * COIN
+
+// Whether the amount is in the valid money range.
+func IsValidAmount(v int64) bool {
+	return v >= 0 && v <= MAX_MONEY
+}
+
+// Add two amounts, return error if any of them or the sum is out of money range.
+func AddAmount(a int64, b int64) (int64, error) {
+	if !IsValidAmount(a) || !IsValidAmount(b) {
+		return 0, fmt.Errorf("amount out of range: %d, %d", a, b)
+	}
+	sum := a + b
+	if !IsValidAmount(sum) {
+		return 0, fmt.Errorf("sum of amounts out of range: %d + %d", a, b)
+	}
+	return sum, nil
+}
+
+// Format the amount in coins with fixed decimals, e.g. 150000000 => "1.50000000".
+func FormatAmount(v int64) string {
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%08d", sign, v/COIN, v%COIN)
+}
+
+// Parse an amount in coins such as "1.5" into base units without going through floating
+// point. At most 8 decimals are allowed.
+func ParseAmount(s string) (int64, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) > 2 || s == "" {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+	whole, frac := parts[0], ""
+	if len(parts) == 2 {
+		frac = parts[1]
+	}
+	if len(frac) > AMOUNT_DECIMALS {
+		return 0, fmt.Errorf("too many decimals in amount: %s", s)
+	}
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+	frac += strings.Repeat("0", AMOUNT_DECIMALS-len(frac))
+
+	var v int64
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid amount: %s", s)
+		}
+		v = v*10 + int64(c-'0')
+		if v > MAX_MONEY {
+			return 0, errors.New("amount is larger than max money: " + s)
+		}
+	}
+	return v, nil
+}
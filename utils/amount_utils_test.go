@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAndFormatAmount(t *testing.T) {
+	v, err := ParseAmount("1.5")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(150000000), v)
+	v, err = ParseAmount("0.00000001")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), v)
+	v, err = ParseAmount(".1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10000000), v)
+
+	for _, s := range []string{"", ".", "1.2.3", "0.000000001", "-1", "1e5", "abc", "21000001"} {
+		_, err = ParseAmount(s)
+		assert.NotNil(t, err, s)
+	}
+
+	assert.Equal(t, "1.50000000", FormatAmount(150000000))
+	assert.Equal(t, "0.00000001", FormatAmount(1))
+	assert.Equal(t, "-2.00000000", FormatAmount(-2*COIN))
+}
+
+func TestAddAmount(t *testing.T) {
+	sum, err := AddAmount(COIN, 2*COIN)
+	assert.Nil(t, err)
+	assert.Equal(t, 3*COIN, sum)
+
+	_, err = AddAmount(MAX_MONEY, 1)
+	assert.NotNil(t, err)
+	_, err = AddAmount(-1, 1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/Luismorlan/btc_in_go/model"
+)
+
+// Bytes reserved in a block for the header, coinbase and encoding overhead when
+// selecting transactions.
+const BLOCK_RESERVED_SIZE = 1000
+
+// Bytes needed to embed a transaction in a block in addition to its own size, which are
+// the field tag and the length prefix.
+const TX_ENCODING_OVERHEAD = 4
+
+// Transactions selected for a new block.
+type BlockTemplate struct {
+	// Transactions in topological order.
+	Txs []*model.Transaction
+	// Total fee of the transactions in base units.
+	Fee int64
+	// Total serialized size of the transactions in bytes, including encoding overhead.
+	Size int64
+}
+
+// Whether fee1/size1 is greater than fee2/size2, compared without division nor overflow.
+func isHigherFeeRate(fee1 int64, size1 int64, fee2 int64, size2 int64) bool {
+	left := new(big.Int).Mul(big.NewInt(fee1), big.NewInt(size2))
+	right := new(big.Int).Mul(big.NewInt(fee2), big.NewInt(size1))
+	return left.Cmp(right) > 0
+}
+
+// Collect the transaction and all its ancestors not selected yet into pkg.
+func getUnselectedPackage(pool *model.TransactionPool, hash string, selected map[string]bool, pkg map[string]bool) {
+	if selected[hash] || pkg[hash] {
+		return
+	}
+	pkg[hash] = true
+	for parent := range pool.TxPool[hash].Parents {
+		getUnselectedPackage(pool, parent, selected, pkg)
+	}
+}
+
+// Select transactions from the pool for a new block, whose total size is at most maxSize
+// bytes, or unlimited if maxSize is not positive. A transaction is scored by the fee rate
+// of its package, which is the transaction together with its ancestors not selected yet,
+// since none of them can be included without the others. This way a child paying a high
+// fee pulls its low fee parents into the block. The package with the highest fee rate is
+// selected first, packages not fitting into the remaining space are skipped.
+// READONLY:
+// * pool
+func SelectBlockTransactions(pool *model.TransactionPool, maxSize int64) BlockTemplate {
+	// Position in topological order, used to order transactions inside a package.
+	order := make(map[string]int)
+	for i, tx := range GetAllTxsInPool(pool) {
+		order[tx.Hash] = i
+	}
+
+	template := BlockTemplate{Txs: []*model.Transaction{}}
+	selected := make(map[string]bool)
+	skipped := make(map[string]bool)
+	for {
+		var best map[string]bool
+		bestHash := ""
+		var bestFee, bestSize int64
+		for hash := range pool.TxPool {
+			if selected[hash] || skipped[hash] {
+				continue
+			}
+			pkg := make(map[string]bool)
+			getUnselectedPackage(pool, hash, selected, pkg)
+			var fee, size int64
+			for h := range pkg {
+				fee += pool.TxPool[h].Fee
+				size += pool.TxPool[h].Size + TX_ENCODING_OVERHEAD
+			}
+			if best == nil || isHigherFeeRate(fee, size, bestFee, bestSize) ||
+				(!isHigherFeeRate(bestFee, bestSize, fee, size) && hash < bestHash) {
+				best, bestHash, bestFee, bestSize = pkg, hash, fee, size
+			}
+		}
+		if best == nil {
+			return template
+		}
+		if maxSize > 0 && template.Size+bestSize > maxSize {
+			skipped[bestHash] = true
+			continue
+		}
+
+		txs := []*model.Transaction{}
+		for h := range best {
+			selected[h] = true
+			txs = append(txs, pool.TxPool[h].Tx)
+		}
+		sort.Slice(txs, func(i, j int) bool { return order[txs[i].Hash] < order[txs[j].Hash] })
+		template.Txs = append(template.Txs, txs...)
+		template.Fee += bestFee
+		template.Size += bestSize
+	}
+}
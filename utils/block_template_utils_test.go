@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func addTestPoolEntry(pool *model.TransactionPool, hash string, fee int64, parents ...string) *model.Transaction {
+	tx := &model.Transaction{Hash: hash}
+	entry := &model.TxPoolEntry{Tx: tx, Fee: fee, Size: 100, Parents: map[string]bool{}, Children: map[string]bool{}}
+	for _, p := range parents {
+		entry.Parents[p] = true
+		pool.TxPool[p].Children[hash] = true
+	}
+	pool.TxPool[hash] = entry
+	pool.Size += entry.Size
+	return tx
+}
+
+func TestSelectBlockTransactions(t *testing.T) {
+	pool := model.NewTransactionPool()
+	// b pays a high fee for its low fee parent a, the package beats c.
+	a := addTestPoolEntry(pool, "aa", 10)
+	b := addTestPoolEntry(pool, "bb", 1000, "aa")
+	c := addTestPoolEntry(pool, "cc", 300)
+
+	template := SelectBlockTransactions(pool, 0)
+	assert.Equal(t, []*model.Transaction{a, b, c}, template.Txs)
+	assert.Equal(t, int64(1310), template.Fee)
+	assert.Equal(t, int64(3*(100+TX_ENCODING_OVERHEAD)), template.Size)
+
+	// c doesn't fit after the package.
+	template = SelectBlockTransactions(pool, 250)
+	assert.Equal(t, []*model.Transaction{a, b}, template.Txs)
+	assert.Equal(t, int64(1010), template.Fee)
+
+	// The package doesn't fit, and b can't be included without a.
+	template = SelectBlockTransactions(pool, 150)
+	assert.Equal(t, []*model.Transaction{c}, template.Txs)
+
+	assert.Equal(t, 0, len(SelectBlockTransactions(model.NewTransactionPool(), 0).Txs))
+}
@@ -2,22 +2,38 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"sort"
 
 	"github.com/Luismorlan/btc_in_go/commands"
+	"github.com/Luismorlan/btc_in_go/config"
 	"github.com/Luismorlan/btc_in_go/model"
 )
 
+// Version of the block format we produce and accept.
+const BLOCK_VERSION = 2
+
+// Block timestamp must be greater than the median timestamp of this many previous blocks.
+const MEDIAN_TIME_SPAN = 11
+
+// Block timestamp must not be ahead of local time for more than this many seconds.
+const MAX_FUTURE_BLOCK_TIME = 2 * 60 * 60
+
+// Number of most recent block hashes in a block locator before the step starts doubling.
+const LOCATOR_DENSE_SPAN = 10
+
 // Create a block from the provided transactions and the previous hash, miner's reward, current
 // 1. Fill in previous hash.
 // 2. Create coinbase transactions (Reward + Tx fee).
 // 3. Fill in transactions provided.
-// 4. Mine the block.
+// 4. Fill in block header with merkle roots and timestamp.
+// 5. Mine the block.
 // Also, **input ledger must be a deep copy because it will be change permanently.**
-func CreateNewBlock(txs []*model.Transaction, prevHash string, reward float64, height int64, pk []byte, l *model.Ledger, difficulty int, ctl chan commands.Command) (*model.Block, commands.Command, []*model.Transaction, error) {
+func CreateNewBlock(txs []*model.Transaction, prevHash string, reward int64, ctx ValidationContext, pk PublicKey, l *model.Ledger, difficulty int, timestamp int64, ctl chan commands.Command) (*model.Block, commands.Command, []*model.Transaction, error) {
 	origL := GetLedgerDeepCopy(l)
 
-	errTxs, err := HandleTransactions(txs, l)
+	errTxs, err := HandleTransactions(txs, l, nil /*undo=*/, ctx)
 	if err != nil {
 		return nil, commands.NewDefaultCommand(), errTxs, err
 	}
@@ -29,9 +45,20 @@ func CreateNewBlock(txs []*model.Transaction, prevHash string, reward float64, h
 	}
 
 	block := model.Block{
-		PrevHash: prevHash,
 		Txs:      txs,
-		Coinbase: CreateCoinbaseTx(reward+fee, pk, height),
+		Coinbase: CreateCoinbaseTx(reward+fee, pk, ctx.Height),
+	}
+	merkleRoot, witnessRoot, err := ComputeBlockRoots(&block)
+	if err != nil {
+		return nil, commands.NewDefaultCommand(), []*model.Transaction{}, err
+	}
+	block.Header = &model.BlockHeader{
+		Version:     BLOCK_VERSION,
+		PrevHash:    prevHash,
+		MerkleRoot:  merkleRoot,
+		WitnessRoot: witnessRoot,
+		Timestamp:   timestamp,
+		Bits:        int64(difficulty),
 	}
 
 	c, err := Mine(&block, difficulty, ctl)
@@ -42,17 +69,25 @@ func CreateNewBlock(txs []*model.Transaction, prevHash string, reward float64, h
 // difficulty - how many leading zeros
 // Always listen for command interruption and stop mining at any time.
 // This process will only terminate when receive signal.
+// Only the header is hashed, and it is serialized only once, each iteration simply
+// overwrites the nounce at the end of the header bytes.
 func Mine(block *model.Block, difficulty int, ctl chan commands.Command) (commands.Command, error) {
+	headerBytes, err := GetBlockHeaderBytes(block.Header)
+	if err != nil {
+		return commands.NewDefaultCommand(), err
+	}
+	nounceOffset := len(headerBytes) - 8
 	for i := 0; i < int(^uint(0)>>1); i++ {
 		// time.Sleep(time.Second)
 		select {
 		case c := <-ctl:
 			return c, errors.New("mining terminated, new block received or explicit termination")
 		default:
-			block.Nounce = int64(i)
-			isMatched, digest := MatchDifficulty(block, difficulty)
-			if isMatched {
-				block.Hash = digest
+			copy(headerBytes[nounceOffset:], Int64ToFixedBytes(int64(i)))
+			digest := SHA256(headerBytes)
+			if ByteHasLeadingZeros(digest, difficulty) {
+				block.Header.Nounce = int64(i)
+				block.Hash = BytesToHex(digest)
 				return commands.NewDefaultCommand(), nil
 			}
 		}
@@ -60,50 +95,158 @@ func Mine(block *model.Block, difficulty int, ctl chan commands.Command) (comman
 	return commands.NewDefaultCommand(), errors.New("failed to find any nounce")
 }
 
-// Get block bytes without its hash.
-func GetBlockBytes(block *model.Block) ([]byte, error) {
-	var rawBlock []byte
+// Convert a hex hash into exactly 32 bytes, shorter hashes (e.g. genesis) are left padded with 0.
+func hashToFixedBytes(hash string) ([]byte, error) {
+	b, err := HexToBytes(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 32 {
+		return nil, errors.New("hash is longer than 32 bytes: " + hash)
+	}
+	return append(make([]byte, 32-len(b)), b...), nil
+}
+
+// Get block header bytes, which is what the block hash is computed from. Nounce is always
+// the last 8 bytes.
+func GetBlockHeaderBytes(header *model.BlockHeader) ([]byte, error) {
+	if header == nil {
+		return nil, errors.New("block header is missing")
+	}
+	var rawHeader []byte
 
-	// convert nounce to bytes
-	nounceBytes := Int64ToBytes(block.Nounce)
-	rawBlock = append(rawBlock, nounceBytes...)
+	rawHeader = append(rawHeader, Int64ToFixedBytes(int64(header.Version))[4:]...)
 
-	// convert preHash to bytes
-	preHashBytes, err := HexToBytes(block.PrevHash)
+	prevHashBytes, err := hashToFixedBytes(header.PrevHash)
 	if err != nil {
 		return nil, err
 	}
-	rawBlock = append(rawBlock, preHashBytes...)
+	rawHeader = append(rawHeader, prevHashBytes...)
 
-	// convert transactions to bytes
-	for i := 0; i < len(block.Txs); i++ {
-		tx := block.Txs[i]
-		txBytes, err := GetTransactionBytes(tx, true /*withHash*/)
-		if err != nil {
-			return nil, err
-		}
-		rawBlock = append(rawBlock, txBytes...)
+	merkleRootBytes, err := hashToFixedBytes(header.MerkleRoot)
+	if err != nil {
+		return nil, err
 	}
+	rawHeader = append(rawHeader, merkleRootBytes...)
 
-	// covert coinbase to bytes
-	coinbaseBytes, err := GetTransactionBytes(block.Coinbase, true /*withHash*/)
+	witnessRootBytes, err := hashToFixedBytes(header.WitnessRoot)
 	if err != nil {
 		return nil, err
 	}
-	rawBlock = append(rawBlock, coinbaseBytes...)
+	rawHeader = append(rawHeader, witnessRootBytes...)
+
+	rawHeader = append(rawHeader, Int64ToFixedBytes(header.Timestamp)...)
+	rawHeader = append(rawHeader, Int64ToFixedBytes(header.Bits)...)
+	rawHeader = append(rawHeader, Int64ToFixedBytes(header.Nounce)...)
 
-	return rawBlock, nil
+	return rawHeader, nil
 }
 
 func MatchDifficulty(block *model.Block, difficulty int) (bool, string) {
-	blockBytes, err := GetBlockBytes(block)
+	headerBytes, err := GetBlockHeaderBytes(block.Header)
 	if err != nil {
 		return false, ""
 	}
-	digest := SHA256(blockBytes)
+	digest := SHA256(headerBytes)
 	return ByteHasLeadingZeros(digest, difficulty), BytesToHex(digest)
 }
 
+// Check the block header on its own: version, difficulty range, proof of work, and that
+// it hashes to the block hash. now is the local unix time, the header shouldn't come from
+// too far in the future.
+func CheckBlockHeader(hash string, header *model.BlockHeader, now int64) error {
+	if header == nil {
+		return errors.New("block is missing header: " + hash)
+	}
+	if header.Version != BLOCK_VERSION {
+		return fmt.Errorf("unknown block version: %d", header.Version)
+	}
+
+	// Difficulty and hash should match. Whether the difficulty is the required one is
+	// checked against the parent.
+	if header.Bits < 0 || header.Bits > MAX_DIFFICULTY {
+		return fmt.Errorf("block difficulty %d is out of range", header.Bits)
+	}
+	headerBytes, err := GetBlockHeaderBytes(header)
+	if err != nil {
+		return err
+	}
+	digest := SHA256(headerBytes)
+	if !ByteHasLeadingZeros(digest, int(header.Bits)) {
+		return errors.New("match difficulty failed for block: " + hash)
+	}
+	if BytesToHex(digest) != hash {
+		return errors.New("block hash is invalid")
+	}
+
+	if header.Timestamp > now+MAX_FUTURE_BLOCK_TIME {
+		return fmt.Errorf("block timestamp %d is too far in the future", header.Timestamp)
+	}
+	return nil
+}
+
+// Check the block header against its parent: difficulty must be the one derived from the
+// parent chain, and timestamp must be after median time of the previous blocks.
+// READONLY:
+// * parent
+func CheckBlockHeaderContext(header *model.BlockHeader, parent *model.BlockWrapper, c config.AppConfig) error {
+	if header.PrevHash != parent.B.Hash {
+		return errors.New("block header doesn't follow its parent: " + parent.B.Hash)
+	}
+	if required := GetNextDifficulty(parent, c); header.Bits != required {
+		return fmt.Errorf("block difficulty %d doesn't match required difficulty %d", header.Bits, required)
+	}
+	if mtp := GetMedianTimePast(parent); header.Timestamp <= mtp {
+		return fmt.Errorf("block timestamp %d is not after median time past %d", header.Timestamp, mtp)
+	}
+	return nil
+}
+
+// Return the hash of the block with the given header.
+func GetBlockHeaderHash(header *model.BlockHeader) (string, error) {
+	headerBytes, err := GetBlockHeaderBytes(header)
+	if err != nil {
+		return "", err
+	}
+	return BytesToHex(SHA256(headerBytes)), nil
+}
+
+// Return the block locator of the given block: hashes from the block back to genesis, the
+// most recent LOCATOR_DENSE_SPAN blocks one by one and then with exponentially increasing
+// steps. Genesis always comes last. A peer finds the first hash on its main chain to
+// know where our chains fork, no matter how long our chains are.
+// READONLY:
+// * bw
+func GetBlockLocator(bw *model.BlockWrapper) []string {
+	locator := []string{}
+	step := int64(1)
+	for {
+		locator = append(locator, bw.B.Hash)
+		if bw.Height == 0 {
+			return locator
+		}
+		if len(locator) >= LOCATOR_DENSE_SPAN {
+			step *= 2
+		}
+		height := bw.Height - step
+		if height < 0 {
+			height = 0
+		}
+		bw = GetAncestor(bw, height)
+	}
+}
+
+// Return the median timestamp of the given block and its ancestors, at most MEDIAN_TIME_SPAN
+// blocks are considered.
+func GetMedianTimePast(bw *model.BlockWrapper) int64 {
+	timestamps := []int64{}
+	for b := bw; b != nil && len(timestamps) < MEDIAN_TIME_SPAN; b = b.Parent {
+		timestamps = append(timestamps, b.B.GetHeader().GetTimestamp())
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	return timestamps[len(timestamps)/2]
+}
+
 func ByteHasLeadingZeros(bytes []byte, difficulty int) bool {
 	numOfZeroBytes := difficulty / 8
 	numOfZeroBits := difficulty % 8
@@ -120,7 +263,25 @@ func ByteHasLeadingZeros(bytes []byte, difficulty int) bool {
 			return false
 		}
 	}
+	if numOfZeroBits == 0 {
+		return true
+	}
 	nextByte := bytes[numOfZeroBytes]
 
 	return (nextByte>>byte(8-numOfZeroBits))&0xFF == 0
 }
+
+// Return the last common ancestor of the two blocks.
+func GetForkPoint(a *model.BlockWrapper, b *model.BlockWrapper) *model.BlockWrapper {
+	for a.Height > b.Height {
+		a = a.Parent
+	}
+	for b.Height > a.Height {
+		b = b.Parent
+	}
+	for a != b {
+		a = a.Parent
+		b = b.Parent
+	}
+	return a
+}
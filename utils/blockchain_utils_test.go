@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/Luismorlan/btc_in_go/commands"
@@ -8,36 +9,56 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func createTestBlock() model.Block {
-	return model.Block{
-		PrevHash: "00ab",
+func createTestBlock() *model.Block {
+	block := &model.Block{
 		Txs: []*model.Transaction{
 			{
 				Hash: "887d",
 			},
 		},
-		Nounce: 3,
 		Coinbase: &model.Transaction{
 			Hash: "00cd",
 		},
 	}
+	merkleRoot, witnessRoot, _ := ComputeBlockRoots(block)
+	block.Header = &model.BlockHeader{
+		Version:     BLOCK_VERSION,
+		PrevHash:    "00ab",
+		MerkleRoot:  merkleRoot,
+		WitnessRoot: witnessRoot,
+		Timestamp:   1620000000,
+		Bits:        8,
+		Nounce:      3,
+	}
+	return block
 }
 
-func TestGetBlockBytes(t *testing.T) {
+func TestGetBlockHeaderBytes(t *testing.T) {
 	testBlock := createTestBlock()
 
-	var expectedBlockBytes []byte
+	actualHeaderBytes, err := GetBlockHeaderBytes(testBlock.Header)
+	assert.Nil(t, err)
+
+	var expectedHeaderBytes []byte
+	expectedHeaderBytes = append(expectedHeaderBytes, 0, 0, 0, BLOCK_VERSION)
+	expectedHeaderBytes = append(expectedHeaderBytes, make([]byte, 30)...)
+	expectedHeaderBytes = append(expectedHeaderBytes, 0x00, 0xab)
+	merkleRootBytes, _ := HexToBytes(testBlock.Header.MerkleRoot)
+	expectedHeaderBytes = append(expectedHeaderBytes, merkleRootBytes...)
+	witnessRootBytes, _ := HexToBytes(testBlock.Header.WitnessRoot)
+	expectedHeaderBytes = append(expectedHeaderBytes, witnessRootBytes...)
+	expectedHeaderBytes = append(expectedHeaderBytes, Int64ToFixedBytes(testBlock.Header.Timestamp)...)
+	expectedHeaderBytes = append(expectedHeaderBytes, Int64ToFixedBytes(testBlock.Header.Bits)...)
+	expectedHeaderBytes = append(expectedHeaderBytes, Int64ToFixedBytes(testBlock.Header.Nounce)...)
+	assert.Equal(t, expectedHeaderBytes, actualHeaderBytes)
 
-	actualBlockBytes, _ := GetBlockBytes(&testBlock)
+	// Transactions are committed by merkle root only, so they don't change the header bytes.
+	testBlock.Txs = nil
+	headerBytes, _ := GetBlockHeaderBytes(testBlock.Header)
+	assert.Equal(t, actualHeaderBytes, headerBytes)
 
-	expectedBlockBytes = append(expectedBlockBytes, Int64ToBytes(testBlock.Nounce)...)
-	preHashBytes, _ := HexToBytes(testBlock.PrevHash)
-	expectedBlockBytes = append(expectedBlockBytes, preHashBytes...)
-	txBytes, _ := GetTransactionBytes(testBlock.Txs[0], true)
-	expectedBlockBytes = append(expectedBlockBytes, txBytes...)
-	coinbaseBytes, _ := GetTransactionBytes(testBlock.Coinbase, true)
-	expectedBlockBytes = append(expectedBlockBytes, coinbaseBytes...)
-	assert.Equal(t, expectedBlockBytes, actualBlockBytes)
+	_, err = GetBlockHeaderBytes(nil)
+	assert.NotNil(t, err)
 }
 
 func TestMine(t *testing.T) {
@@ -45,10 +66,11 @@ func TestMine(t *testing.T) {
 	testBlock := createTestBlock()
 	testChan := make(chan commands.Command)
 
-	_, actualErr := Mine(&testBlock, testDifficulty, testChan)
+	_, actualErr := Mine(testBlock, testDifficulty, testChan)
 	assert.Nil(t, actualErr)
-	expectedMatched, _ := MatchDifficulty(&testBlock, testDifficulty)
+	expectedMatched, digest := MatchDifficulty(testBlock, testDifficulty)
 	assert.True(t, expectedMatched)
+	assert.Equal(t, digest, testBlock.Hash)
 }
 
 func TestMineInterruption(t *testing.T) {
@@ -63,7 +85,7 @@ func TestMineInterruption(t *testing.T) {
 		}
 	}()
 
-	c, actualErr := Mine(&testBlock, testDifficulty, testChan)
+	c, actualErr := Mine(testBlock, testDifficulty, testChan)
 	assert.Equal(t, c, commands.Command{
 		Op: commands.STOP,
 	})
@@ -76,8 +98,8 @@ func TestMineInterruption(t *testing.T) {
 func TestMatchDifficulty(t *testing.T) {
 	testDifficulty := 8
 	testBlock := createTestBlock()
-	actualMatched, actualDigest := MatchDifficulty(&testBlock, testDifficulty)
-	blockBytes, expectedErr := GetBlockBytes(&testBlock)
+	actualMatched, actualDigest := MatchDifficulty(testBlock, testDifficulty)
+	blockBytes, expectedErr := GetBlockHeaderBytes(testBlock.Header)
 	if expectedErr != nil {
 		assert.Equal(t, "", actualDigest)
 		assert.False(t, actualMatched)
@@ -96,3 +118,18 @@ func TestByteHasLeadingZeros(t *testing.T) {
 	assert.False(t, ByteHasLeadingZeros(testByte, 9))
 	assert.False(t, ByteHasLeadingZeros(testByte, 25))
 }
+
+func TestByteHasLeadingZerosFullLength(t *testing.T) {
+	assert.True(t, ByteHasLeadingZeros([]byte{0, 0}, 16))
+	assert.False(t, ByteHasLeadingZeros([]byte{0, 0}, 17))
+}
+
+func TestGetBlockLocator(t *testing.T) {
+	bw := &model.BlockWrapper{B: &model.Block{Hash: model.GENESIS_HASH}}
+	for i := 1; i <= 30; i++ {
+		bw = &model.BlockWrapper{B: &model.Block{Hash: fmt.Sprint(i)}, Parent: bw, Height: int64(i)}
+	}
+	locator := GetBlockLocator(bw)
+	expected := []string{"30", "29", "28", "27", "26", "25", "24", "23", "22", "21", "19", "15", "7", model.GENESIS_HASH}
+	assert.Equal(t, expected, locator)
+}
@@ -3,7 +3,6 @@ package utils
 import (
 	"encoding/binary"
 	"encoding/hex"
-	"math"
 )
 
 func BytesToHex(bytes []byte) string {
@@ -24,12 +23,26 @@ func Int64ToBytes(i int64) []byte {
 	return b
 }
 
-func Float64ToBytes(f float64) []byte {
+// Encode int64 as fixed 8 bytes in big endian.
+func Int64ToFixedBytes(i int64) []byte {
 	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b[:], math.Float64bits(f))
+	binary.BigEndian.PutUint64(b, uint64(i))
 	return b
 }
 
+// Amounts are encoded as fixed 8 bytes in big endian, so that every possible value
+// fits regardless of its magnitude.
+func AmountToBytes(v int64) []byte {
+	return Int64ToFixedBytes(v)
+}
+
+func BoolToBytes(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
 func IsSameBytes(lhs []byte, rhs []byte) bool {
 	if len(lhs) != len(rhs) {
 		return false
@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"google.golang.org/protobuf/proto"
+)
+
+// Change below this many base units is not worth an output, it's added to the fee instead.
+const DUST_THRESHOLD = 1000
+
+// Maximum number of branches explored by branch-and-bound before giving up.
+const BNB_MAX_TRIES = 100000
+
+// Names of the coin selection strategies, see GetCoinSelector.
+const (
+	LARGEST_FIRST    = "largest_first"
+	BRANCH_AND_BOUND = "branch_and_bound"
+	RANDOM_IMPROVE   = "random_improve"
+)
+
+// A spendable output together with its UTXO.
+type Coin struct {
+	Utxo   model.UTXOLite
+	Output *model.Output
+}
+
+// What coin selection must pay for. Each coin is worth its value minus InputFee, which is
+// called its effective value, and selected coins must be worth at least Target plus
+// BaseFee in total.
+type CoinSelectionParams struct {
+	// Total value of the outputs to pay, in base units.
+	Target int64
+	// Fee of the transaction without any input nor change output.
+	BaseFee int64
+	// Fee of each input.
+	InputFee int64
+	// Fee of the change output.
+	ChangeCost int64
+}
+
+// Coins selected for a transaction.
+type CoinSelection struct {
+	Coins map[model.UTXOLite]*model.Output
+	// Transaction fee in base units, including change too small to be worth an output.
+	Fee int64
+	// Change sent back to the wallet, 0 if there is no change output.
+	Change int64
+}
+
+// CoinSelector chooses which coins a transaction spends.
+type CoinSelector interface {
+	// Select returns a subset of coins whose total effective value is at least Target
+	// plus BaseFee. Coins all have a positive effective value, are sorted by value from
+	// the largest, and are worth enough in total.
+	Select(coins []Coin, p CoinSelectionParams) ([]Coin, error)
+}
+
+// Return the coin selector with the given name. Branch-and-bound falls back to random
+// improvement when there is no exact match.
+func GetCoinSelector(name string) (CoinSelector, error) {
+	switch name {
+	case LARGEST_FIRST:
+		return LargestFirstSelector{}, nil
+	case BRANCH_AND_BOUND:
+		return BranchAndBoundSelector{Fallback: NewRandomImproveSelector()}, nil
+	case RANDOM_IMPROVE:
+		return NewRandomImproveSelector(), nil
+	default:
+		return nil, fmt.Errorf("unknown coin selection strategy %q, must be one of %s", name,
+			strings.Join([]string{LARGEST_FIRST, BRANCH_AND_BOUND, RANDOM_IMPROVE}, ", "))
+	}
+}
+
+// Return parameters to pay the outputs at feeRate base units per FEE_RATE_BYTES bytes,
+// with change sent to change and inputs signed by keys of the same type as sk. Sizes are
+// estimated on the high side, so the fee rate paid is never lower.
+func NewCoinSelectionParams(outputs []*model.Output, feeRate int64, change PublicKey, sk PrivateKey) (CoinSelectionParams, error) {
+	unlocking := script.AppendData(script.AppendData(nil, make([]byte, sk.SignatureSize()+1)), EncodePublicKey(sk.Public()))
+	return newCoinSelectionParams(outputs, feeRate, NewOutput(MAX_MONEY, change), unlocking)
+}
+
+// Like NewCoinSelectionParams, but for spending outputs paying to the hash of the
+// multisig redeem script, with change sent back to it.
+func NewMultiSigCoinSelectionParams(outputs []*model.Output, feeRate int64, redeem []byte) (CoinSelectionParams, error) {
+	unlocking, err := estimateMultiSigUnlocking(redeem)
+	if err != nil {
+		return CoinSelectionParams{}, err
+	}
+	change := &model.Output{Value: MAX_MONEY, Script: script.PayToScriptHash(HashPublicKey(redeem))}
+	return newCoinSelectionParams(outputs, feeRate, change, unlocking)
+}
+
+// Return parameters to pay the outputs, with inputs of the size of unlocking and change
+// like the change output.
+func newCoinSelectionParams(outputs []*model.Output, feeRate int64, change *model.Output, unlocking []byte) (CoinSelectionParams, error) {
+	var target int64
+	var err error
+	for _, output := range outputs {
+		target, err = AddAmount(target, output.Value)
+		if err != nil {
+			return CoinSelectionParams{}, err
+		}
+	}
+	hash := strings.Repeat("f", 64)
+	baseSize := proto.Size(&model.Transaction{Hash: hash, Outputs: outputs, Replaceable: true})
+	inputSize := proto.Size(&model.Transaction{Inputs: []*model.Input{{
+		PrevTxHash: hash,
+		Index:      math.MaxInt32,
+		Script:     unlocking,
+	}}})
+	changeSize := proto.Size(&model.Transaction{Outputs: []*model.Output{change}})
+	return CoinSelectionParams{
+		Target:     target,
+		BaseFee:    GetFeeForSize(feeRate, int64(baseSize)),
+		InputFee:   GetFeeForSize(feeRate, int64(inputSize)),
+		ChangeCost: GetFeeForSize(feeRate, int64(changeSize)),
+	}, nil
+}
+
+// Select coins from utxos with the selector. Coins costing more fee than their value are
+// never spent. Change which is not worth more than DUST_THRESHOLD after paying for its own
+// output is added to the fee.
+// READONLY:
+// * utxos
+func SelectCoins(selector CoinSelector, utxos map[model.UTXOLite]*model.Output, p CoinSelectionParams) (*CoinSelection, error) {
+	coins := []Coin{}
+	var available int64
+	for utxo, output := range utxos {
+		if effectiveValue(output, p) > 0 {
+			coins = append(coins, Coin{Utxo: utxo, Output: output})
+			available += effectiveValue(output, p)
+		}
+	}
+	need := p.Target + p.BaseFee
+	if available < need {
+		return nil, fmt.Errorf("insufficient funds: %s available after input fees, need %s including fee %s",
+			FormatAmount(available), FormatAmount(need), FormatAmount(p.BaseFee))
+	}
+	sort.Slice(coins, func(i, j int) bool {
+		if coins[i].Output.Value != coins[j].Output.Value {
+			return coins[i].Output.Value > coins[j].Output.Value
+		}
+		if coins[i].Utxo.PrevTxHash != coins[j].Utxo.PrevTxHash {
+			return coins[i].Utxo.PrevTxHash < coins[j].Utxo.PrevTxHash
+		}
+		return coins[i].Utxo.Index < coins[j].Utxo.Index
+	})
+
+	selected, err := selector.Select(coins, p)
+	if err != nil {
+		return nil, err
+	}
+	sel := &CoinSelection{
+		Coins: make(map[model.UTXOLite]*model.Output),
+		Fee:   p.BaseFee,
+	}
+	var value int64
+	for _, coin := range selected {
+		sel.Coins[coin.Utxo] = coin.Output
+		sel.Fee += p.InputFee
+		value += coin.Output.Value
+	}
+	change := value - p.Target - sel.Fee
+	if change < 0 {
+		return nil, errors.New("unexpected error: selected coins don't cover amount and fee")
+	}
+	if change-p.ChangeCost >= DUST_THRESHOLD {
+		sel.Fee += p.ChangeCost
+		sel.Change = change - p.ChangeCost
+	} else {
+		sel.Fee += change
+	}
+	return sel, nil
+}
+
+// Value of the output minus the fee to spend it.
+func effectiveValue(output *model.Output, p CoinSelectionParams) int64 {
+	return output.Value - p.InputFee
+}
+
+// Select the largest coins until the amount is covered. This spends as few coins as
+// possible, but usually creates change.
+type LargestFirstSelector struct{}
+
+func (s LargestFirstSelector) Select(coins []Coin, p CoinSelectionParams) ([]Coin, error) {
+	need := p.Target + p.BaseFee
+	var sum int64
+	for i, coin := range coins {
+		sum += effectiveValue(coin.Output, p)
+		if sum >= need {
+			return coins[:i+1], nil
+		}
+	}
+	return nil, errors.New("unexpected error: coins don't cover amount and fee")
+}
+
+// Search for a set of coins matching the amount so closely that no change is needed,
+// which saves the change output and leaves nothing linking the payment to the wallet.
+// If there is no such set, use Fallback.
+type BranchAndBoundSelector struct {
+	Fallback CoinSelector
+}
+
+func (s BranchAndBoundSelector) Select(coins []Coin, p CoinSelectionParams) ([]Coin, error) {
+	need := p.Target + p.BaseFee
+	// Any total below this leaves no change worth an output.
+	upper := need + p.ChangeCost + DUST_THRESHOLD
+	// remaining[i] is the total effective value of coins[i:].
+	remaining := make([]int64, len(coins)+1)
+	for i := len(coins) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + effectiveValue(coins[i].Output, p)
+	}
+
+	var best []Coin
+	var bestExcess int64
+	tries := 0
+	selected := []Coin{}
+	var search func(i int, sum int64)
+	search = func(i int, sum int64) {
+		if tries >= BNB_MAX_TRIES || (best != nil && bestExcess == 0) {
+			return
+		}
+		tries++
+		if sum >= need {
+			if sum < upper && (best == nil || sum-need < bestExcess) {
+				best = append([]Coin{}, selected...)
+				bestExcess = sum - need
+			}
+			return
+		}
+		if i == len(coins) || sum+remaining[i] < need {
+			return
+		}
+		v := effectiveValue(coins[i].Output, p)
+		selected = append(selected, coins[i])
+		search(i+1, sum+v)
+		selected = selected[:len(selected)-1]
+		// Excluding a coin and then including an equal one is a branch already explored.
+		j := i + 1
+		for j < len(coins) && effectiveValue(coins[j].Output, p) == v {
+			j++
+		}
+		search(j, sum)
+	}
+	search(0, 0)
+
+	if best != nil {
+		return best, nil
+	}
+	if s.Fallback == nil {
+		return nil, errors.New("no set of coins matches the amount without change")
+	}
+	return s.Fallback.Select(coins, p)
+}
+
+// Select random coins until the amount is covered, then keep adding random coins while
+// it brings the change closer to the amount. Change of a size similar to payments keeps
+// the wallet with useful coins and makes it harder to tell which output is the payment.
+type RandomImproveSelector struct {
+	Rand *rand.Rand
+}
+
+// Create a random improvement selector seeded with the current time.
+func NewRandomImproveSelector() RandomImproveSelector {
+	return RandomImproveSelector{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (s RandomImproveSelector) Select(coins []Coin, p CoinSelectionParams) ([]Coin, error) {
+	need := p.Target + p.BaseFee
+	order := s.Rand.Perm(len(coins))
+
+	selected := []Coin{}
+	var sum int64
+	next := 0
+	for ; next < len(order) && sum < need; next++ {
+		coin := coins[order[next]]
+		selected = append(selected, coin)
+		sum += effectiveValue(coin.Output, p)
+	}
+	if sum < need {
+		return nil, errors.New("unexpected error: coins don't cover amount and fee")
+	}
+
+	// Ideally the change is the amount itself, and never more than twice the amount.
+	ideal := 2 * need
+	for ; next < len(order); next++ {
+		coin := coins[order[next]]
+		v := effectiveValue(coin.Output, p)
+		if sum+v <= 3*need && abs(ideal-(sum+v)) < abs(ideal-sum) {
+			selected = append(selected, coin)
+			sum += v
+		}
+	}
+	return selected, nil
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
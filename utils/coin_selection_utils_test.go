@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// Create UTXOs with the given values.
+func createTestCoins(values ...int64) map[model.UTXOLite]*model.Output {
+	utxos := make(map[model.UTXOLite]*model.Output)
+	for i, v := range values {
+		utxos[model.UTXOLite{PrevTxHash: fmt.Sprintf("%02x", i), Index: 0}] = &model.Output{Value: v}
+	}
+	return utxos
+}
+
+// Return the total value of the selected coins.
+func getSelectedValue(sel *CoinSelection) int64 {
+	var v int64
+	for _, output := range sel.Coins {
+		v += output.Value
+	}
+	return v
+}
+
+// Return the sorted hashes of the selected coins.
+func getSelectedHashes(sel *CoinSelection) []string {
+	hashes := make(map[string]bool)
+	for utxo := range sel.Coins {
+		hashes[utxo.PrevTxHash] = true
+	}
+	return sortedHashes(hashes)
+}
+
+func TestSelectCoinsLargestFirst(t *testing.T) {
+	utxos := createTestCoins(10000, 50000, 20000, 400)
+	p := CoinSelectionParams{Target: 55000, BaseFee: 500, InputFee: 500, ChangeCost: 100}
+
+	sel, err := SelectCoins(LargestFirstSelector{}, utxos, p)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(sel.Coins))
+	assert.Equal(t, int64(1600), sel.Fee)
+	assert.Equal(t, int64(13400), sel.Change)
+	assert.Equal(t, getSelectedValue(sel), p.Target+sel.Fee+sel.Change)
+
+	// The coin of 400 costs more to spend than it's worth.
+	p.Target = 80000 - 2000 + 1
+	_, err = SelectCoins(LargestFirstSelector{}, utxos, p)
+	assert.NotNil(t, err)
+}
+
+func TestSelectCoinsDustChange(t *testing.T) {
+	utxos := createTestCoins(10000)
+	p := CoinSelectionParams{Target: 8000, BaseFee: 500, InputFee: 500, ChangeCost: 100}
+
+	// Change of 1000 minus the cost of its output is dust, so it goes to the fee.
+	sel, err := SelectCoins(LargestFirstSelector{}, utxos, p)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2000), sel.Fee)
+	assert.Equal(t, int64(0), sel.Change)
+}
+
+func TestSelectCoinsBranchAndBound(t *testing.T) {
+	utxos := createTestCoins(50000, 30000, 20000, 12000, 8000)
+	p := CoinSelectionParams{Target: 40000, BaseFee: 0, InputFee: 500, ChangeCost: 100}
+
+	// 30000 + 12000 - 2 * 500 is close enough to need no change, the rest goes to fee.
+	sel, err := SelectCoins(BranchAndBoundSelector{}, utxos, p)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"01", "03"}, getSelectedHashes(sel))
+	assert.Equal(t, int64(0), sel.Change)
+	assert.Equal(t, int64(2000), sel.Fee)
+
+	// An exact match exists for 20000 - 500.
+	p.Target = 19500
+	sel, err = SelectCoins(BranchAndBoundSelector{}, utxos, p)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"02"}, getSelectedHashes(sel))
+	assert.Equal(t, int64(500), sel.Fee)
+
+	// No match without change, fall back if possible.
+	p.Target = 100
+	_, err = SelectCoins(BranchAndBoundSelector{}, utxos, p)
+	assert.NotNil(t, err)
+	sel, err = SelectCoins(BranchAndBoundSelector{Fallback: LargestFirstSelector{}}, utxos, p)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50000-100-500-100), sel.Change)
+}
+
+func TestSelectCoinsRandomImprove(t *testing.T) {
+	values := []int64{}
+	for i := 1; i <= 50; i++ {
+		values = append(values, int64(i)*1000)
+	}
+	utxos := createTestCoins(values...)
+	p := CoinSelectionParams{Target: 30000, BaseFee: 200, InputFee: 100, ChangeCost: 100}
+	need := p.Target + p.BaseFee
+
+	for seed := int64(0); seed < 20; seed++ {
+		sel, err := SelectCoins(RandomImproveSelector{Rand: rand.New(rand.NewSource(seed))}, utxos, p)
+		assert.Nil(t, err)
+		effective := getSelectedValue(sel) - int64(len(sel.Coins))*p.InputFee
+		assert.True(t, effective >= need)
+		assert.Equal(t, getSelectedValue(sel), p.Target+sel.Fee+sel.Change)
+	}
+}
+
+func TestGetCoinSelector(t *testing.T) {
+	for _, name := range []string{LARGEST_FIRST, BRANCH_AND_BOUND, RANDOM_IMPROVE} {
+		_, err := GetCoinSelector(name)
+		assert.Nil(t, err)
+	}
+	_, err := GetCoinSelector("smallest_first")
+	assert.NotNil(t, err)
+}
+
+func TestNewCoinSelectionParams(t *testing.T) {
+	sk := createTestKey(t)
+	pk := sk.Public()
+	l := model.NewLedger()
+	for i := int64(1); i <= 3; i++ {
+		ProcessInputsAndOutputs(CreateCoinbaseTx(i*100000, pk, i), l, nil, ValidationContext{Height: i})
+	}
+	outputs := []*model.Output{NewOutput(250000, pk)}
+	p, err := NewCoinSelectionParams(outputs, 5000, pk, sk)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(250000), p.Target)
+
+	sel, err := SelectCoins(LargestFirstSelector{}, l.L, p)
+	assert.Nil(t, err)
+	tx, err := CreatePendingTransaction(sk, sel.Coins, outputs, sel.Fee)
+	assert.Nil(t, err)
+	tx.Replaceable = true
+	assert.Nil(t, SignTransaction(sk, tx, sel.Coins))
+	assert.Nil(t, IsValidTransaction(tx, l, ValidationContext{}))
+	// The fee rate paid is at least the one asked for.
+	assert.True(t, sel.Fee >= GetFeeForSize(5000, int64(proto.Size(tx))))
+}
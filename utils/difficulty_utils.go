@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"math/big"
+
+	"github.com/Luismorlan/btc_in_go/config"
+	"github.com/Luismorlan/btc_in_go/model"
+)
+
+// Difficulty can't go lower than this, otherwise every hash is a valid block.
+const MIN_DIFFICULTY = 1
+
+// Difficulty can't go higher than the number of bits in a SHA256 hash.
+const MAX_DIFFICULTY = 256
+
+// Return the ancestor of the given block at height, or nil if height is out of range.
+func GetAncestor(bw *model.BlockWrapper, height int64) *model.BlockWrapper {
+	if height < 0 || height > bw.Height {
+		return nil
+	}
+	for bw != nil && bw.Height > height {
+		bw = bw.Parent
+	}
+	return bw
+}
+
+// Return the difficulty of the block. Genesis has no header and uses the initial difficulty.
+func getBlockDifficulty(bw *model.BlockWrapper, c config.AppConfig) int64 {
+	if bw.B.GetHeader() == nil {
+		return int64(c.DIFFICULTY)
+	}
+	return bw.B.Header.Bits
+}
+
+// Compute the difficulty of the next period given difficulty of the current period and
+// how long it took to mine the period. Difficulty is counted in leading zero bits, and
+// each bit doubles the expected work, so difficulty only changes when blocks came at
+// least twice too fast or too slow. The change is clamped to MAX_RETARGET_STEP bits.
+func CalcNextDifficulty(bits int64, actualTimespan int64, c config.AppConfig) int64 {
+	expectedTimespan := c.RETARGET_INTERVAL * c.TARGET_BLOCK_INTERVAL
+	if actualTimespan < 1 {
+		actualTimespan = 1
+	}
+
+	var up, down int64 = 0, 0
+	for up < c.MAX_RETARGET_STEP && actualTimespan<<(up+1) <= expectedTimespan {
+		// Blocks are too fast, require more leading zeros.
+		up++
+	}
+	for down < c.MAX_RETARGET_STEP && expectedTimespan<<(down+1) <= actualTimespan {
+		// Blocks are too slow, require less leading zeros.
+		down++
+	}
+
+	next := bits + up - down
+	if next < MIN_DIFFICULTY {
+		next = MIN_DIFFICULTY
+	}
+	if next > MAX_DIFFICULTY {
+		next = MAX_DIFFICULTY
+	}
+	return next
+}
+
+// Return the difficulty required for a block on top of parent. Difficulty is retargeted
+// every RETARGET_INTERVAL blocks based on the timestamps of the last RETARGET_INTERVAL
+// blocks, and stays the same within a period. Retargeting is disabled if RETARGET_INTERVAL
+// is not positive, in which case DIFFICULTY is always used.
+// READONLY:
+// * parent
+func GetNextDifficulty(parent *model.BlockWrapper, c config.AppConfig) int64 {
+	if c.RETARGET_INTERVAL <= 0 {
+		return int64(c.DIFFICULTY)
+	}
+	bits := getBlockDifficulty(parent, c)
+	height := parent.Height + 1
+	if height%c.RETARGET_INTERVAL != 0 {
+		return bits
+	}
+	// Genesis has no meaningful timestamp, so the first period starts at height 1.
+	first := GetAncestor(parent, parent.Height-c.RETARGET_INTERVAL)
+	if first == nil || first.Height == 0 {
+		return bits
+	}
+	actualTimespan := parent.B.Header.Timestamp - first.B.Header.Timestamp
+	return CalcNextDifficulty(bits, actualTimespan, c)
+}
+
+// Expected number of hashes to find a block with the given difficulty, which is 2^bits.
+func GetBlockWork(bits int64) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits))
+}
+
+// Fork choice rule: whether candidate should replace current as the tail. The chain
+// with more total work wins. On a tie the block with smaller hash wins, so that all
+// nodes choose the same tail regardless of the order blocks arrive.
+func IsBetterTip(candidate *model.BlockWrapper, current *model.BlockWrapper) bool {
+	cmp := candidate.TotalWork.Cmp(current.TotalWork)
+	if cmp != 0 {
+		return cmp > 0
+	}
+	return candidate.B.Hash < current.B.Hash
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/config"
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestDifficultyConfig() config.AppConfig {
+	return config.AppConfig{
+		DIFFICULTY:            10,
+		RETARGET_INTERVAL:     5,
+		TARGET_BLOCK_INTERVAL: 10,
+		MAX_RETARGET_STEP:     2,
+	}
+}
+
+// Create a chain of n blocks on top of genesis, each block comes interval seconds after
+// its parent with the given difficulty. Return the last block.
+func createTestChain(n int, interval int64, bits int64) *model.BlockWrapper {
+	tail := model.NewBlockChain().Tail
+	for i := 0; i < n; i++ {
+		tail = &model.BlockWrapper{
+			B: &model.Block{Header: &model.BlockHeader{
+				Timestamp: 1000 + int64(i)*interval,
+				Bits:      bits,
+			}},
+			Parent: tail,
+			Height: tail.Height + 1,
+		}
+	}
+	return tail
+}
+
+func TestCalcNextDifficulty(t *testing.T) {
+	c := createTestDifficultyConfig()
+	// Expected timespan is 50 seconds.
+	assert.Equal(t, int64(10), CalcNextDifficulty(10, 50, c))
+	assert.Equal(t, int64(10), CalcNextDifficulty(10, 99, c))
+	assert.Equal(t, int64(10), CalcNextDifficulty(10, 26, c))
+	assert.Equal(t, int64(11), CalcNextDifficulty(10, 25, c))
+	assert.Equal(t, int64(9), CalcNextDifficulty(10, 100, c))
+	// Clamped by max step.
+	assert.Equal(t, int64(12), CalcNextDifficulty(10, 0, c))
+	assert.Equal(t, int64(8), CalcNextDifficulty(10, 100000, c))
+	// Never lower than min difficulty.
+	assert.Equal(t, int64(MIN_DIFFICULTY), CalcNextDifficulty(2, 100000, c))
+}
+
+func TestGetNextDifficulty(t *testing.T) {
+	c := createTestDifficultyConfig()
+	// Genesis uses the initial difficulty.
+	assert.Equal(t, int64(10), GetNextDifficulty(model.NewBlockChain().Tail, c))
+	// Within a period difficulty doesn't change even if blocks are too fast.
+	assert.Equal(t, int64(10), GetNextDifficulty(createTestChain(6, 1, 10), c))
+	// Not enough blocks for the first full period.
+	assert.Equal(t, int64(10), GetNextDifficulty(createTestChain(4, 1, 10), c))
+	// Blocks are 4x too fast.
+	assert.Equal(t, int64(12), GetNextDifficulty(createTestChain(9, 2, 10), c))
+	// Blocks are 2x too slow.
+	assert.Equal(t, int64(9), GetNextDifficulty(createTestChain(9, 20, 10), c))
+	// Blocks are on time.
+	assert.Equal(t, int64(10), GetNextDifficulty(createTestChain(9, 10, 10), c))
+
+	// Disabled retargeting always uses the configured difficulty.
+	c.RETARGET_INTERVAL = 0
+	assert.Equal(t, int64(10), GetNextDifficulty(createTestChain(9, 2, 12), c))
+}
+
+func TestIsBetterTip(t *testing.T) {
+	createTip := func(hash string, work int64) *model.BlockWrapper {
+		return &model.BlockWrapper{B: &model.Block{Hash: hash}, TotalWork: big.NewInt(work)}
+	}
+	assert.True(t, IsBetterTip(createTip("bb", 3), createTip("aa", 2)))
+	assert.False(t, IsBetterTip(createTip("aa", 2), createTip("bb", 3)))
+	// Tie is broken by the smaller hash.
+	assert.True(t, IsBetterTip(createTip("aa", 2), createTip("bb", 2)))
+	assert.False(t, IsBetterTip(createTip("bb", 2), createTip("aa", 2)))
+	assert.Equal(t, big.NewInt(32), GetBlockWork(5))
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"sort"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// Default number of recent blocks to estimate fee rate from.
+const FEE_ESTIMATE_BLOCKS = 6
+
+// A block at least this percentage full is considered full for fee estimation. A
+// transaction paying less than what got into a full block might have to wait.
+const FULL_BLOCK_PERCENT = 50
+
+// Return the lowest fee rate paid by a transaction in the block, or 0 if the block was not
+// full, which means any fee rate would have been included. The undo record provides the
+// outputs spent by the block.
+// READONLY:
+// * b
+// * undo
+func GetBlockMinFeeRate(b *model.Block, undo *model.BlockUndo, maxBlockSize int64) (int64, error) {
+	if len(b.Txs) == 0 || maxBlockSize <= 0 || int64(proto.Size(b))*100 < maxBlockSize*FULL_BLOCK_PERCENT {
+		return 0, nil
+	}
+	l := model.NewLedger()
+	for _, e := range undo.Spent {
+		l.L[e.Utxo] = e.Output
+	}
+	fees, err := GetTxFees(b.Txs, l)
+	if err != nil {
+		return 0, err
+	}
+	var minRate int64 = -1
+	for i, tx := range b.Txs {
+		rate := GetFeeRate(fees[i], int64(proto.Size(tx)))
+		if minRate < 0 || rate < minRate {
+			minRate = rate
+		}
+	}
+	return minRate, nil
+}
+
+// Return the lowest fee rate of transactions selected into the next block of maxSize
+// bytes, or 0 if all pool transactions fit, which means any fee rate would be included.
+// READONLY:
+// * pool
+func GetPoolMinFeeRate(pool *model.TransactionPool, maxSize int64) int64 {
+	template := SelectBlockTransactions(pool, maxSize)
+	if len(template.Txs) == len(pool.TxPool) {
+		return 0
+	}
+	var minRate int64 = -1
+	for _, tx := range template.Txs {
+		entry := pool.TxPool[tx.Hash]
+		if rate := GetFeeRate(entry.Fee, entry.Size); minRate < 0 || rate < minRate {
+			minRate = rate
+		}
+	}
+	if minRate < 0 {
+		return 0
+	}
+	return minRate
+}
+
+// Estimate the fee rate for a transaction to be included soon, which is the highest of:
+// 1. The median of the lowest fee rates included in recent blocks.
+// 2. The lowest fee rate selected from the pool into the next block.
+// 3. The minimum fee rate to enter the pool.
+func EstimateFeeRate(blockRates []int64, poolRate int64, minFeeRate int64) int64 {
+	rate := minFeeRate
+	if poolRate > rate {
+		rate = poolRate
+	}
+	if len(blockRates) > 0 {
+		sorted := append([]int64{}, blockRates...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		if median := sorted[len(sorted)/2]; median > rate {
+			rate = median
+		}
+	}
+	return rate
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetPoolMinFeeRate(t *testing.T) {
+	pool := model.NewTransactionPool()
+	addTestPoolEntry(pool, "aa", 300)
+	addTestPoolEntry(pool, "bb", 200)
+	addTestPoolEntry(pool, "cc", 100)
+	// Everything fits into the next block.
+	assert.Equal(t, int64(0), GetPoolMinFeeRate(pool, 0))
+	// Only aa and bb fit, a new transaction must beat bb.
+	assert.Equal(t, int64(2000), GetPoolMinFeeRate(pool, 2*(100+TX_ENCODING_OVERHEAD)))
+}
+
+func TestEstimateFeeRate(t *testing.T) {
+	assert.Equal(t, int64(1000), EstimateFeeRate([]int64{}, 0, 1000))
+	assert.Equal(t, int64(3000), EstimateFeeRate([]int64{5000, 0, 3000}, 2000, 1000))
+	assert.Equal(t, int64(4000), EstimateFeeRate([]int64{5000, 0, 3000}, 4000, 1000))
+}
+
+func TestGetBlockMinFeeRate(t *testing.T) {
+	sk := createTestKey(t)
+	cb := CreateCoinbaseTx(100000, sk.Public(), 1)
+	l := model.NewLedger()
+	undo := &model.BlockUndo{}
+	ProcessInputsAndOutputs(cb, l, nil, ValidationContext{})
+
+	// tx2 spends an output of tx1 in the same block.
+	tx1, err := CreatePendingTransaction(sk, map[model.UTXOLite]*model.Output{{PrevTxHash: cb.Hash, Index: 0}: cb.Outputs[0]},
+		[]*model.Output{NewOutput(50000, sk.Public())}, 5000)
+	assert.Nil(t, err)
+	tx2, err := CreatePendingTransaction(sk, map[model.UTXOLite]*model.Output{{PrevTxHash: tx1.Hash, Index: 0}: tx1.Outputs[0]},
+		[]*model.Output{NewOutput(10000, sk.Public())}, 1000)
+	assert.Nil(t, err)
+	b := &model.Block{Txs: []*model.Transaction{tx1, tx2}}
+	_, err = HandleTransactions(b.Txs, l, undo, ValidationContext{})
+	assert.Nil(t, err)
+
+	// Outputs spent from before the block come from the undo record.
+	fees, err := GetTxFees(b.Txs, &model.Ledger{L: map[model.UTXOLite]*model.Output{undo.Spent[0].Utxo: undo.Spent[0].Output}})
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{5000, 1000}, fees)
+
+	size := int64(proto.Size(b))
+	rate, err := GetBlockMinFeeRate(b, undo, size)
+	assert.Nil(t, err)
+	assert.Equal(t, GetFeeRate(1000, int64(proto.Size(tx2))), rate)
+	// The block is less than half full.
+	rate, err = GetBlockMinFeeRate(b, undo, 3*size)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), rate)
+}
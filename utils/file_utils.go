@@ -1,33 +1,45 @@
 package utils
 
 import (
-	"crypto/rsa"
 	"io/ioutil"
 	"log"
 	"os"
+
+	"github.com/Luismorlan/btc_in_go/model"
 )
 
 // This function is called in startup time.
-// ParseKeyFile returns key pair from the given path. This function will exit on any error
-// because there's no need to continue if we cannot get key.
-func ParseKeyFile(path string, rsaLen int) *rsa.PrivateKey {
+// ParseKeyFile returns key pair from the given path, or generates a key of the type if
+// there is no such file. An existing key is used whatever its type. This function will
+// exit on any error because there's no need to continue if we cannot get key.
+func ParseKeyFile(path string, t model.KeyType, rsaLen int) PrivateKey {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		sk, _ := GenerateKeyPair(rsaLen)
+		sk, err := GenerateKey(t, rsaLen)
+		if err != nil {
+			log.Fatalln("fail to generate sk: " + err.Error())
+		}
 		WritePrivateKeyToFile(sk, path)
 		return sk
 	}
 	sk := ReadKeyFromPath(path)
+	if sk.Type() != t {
+		log.Printf("using %s key in %s instead of generating a %s key", sk.Type(), path, t)
+	}
 	return sk
 }
 
 // Write the given private key into file in bytes. Exit if fail to write
-func WritePrivateKeyToFile(sk *rsa.PrivateKey, path string) {
+func WritePrivateKeyToFile(sk PrivateKey, path string) {
+	data, err := sk.MarshalPEM()
+	if err != nil {
+		log.Fatalln("fail to write sk: " + err.Error())
+	}
 	f, err := os.Create(path)
 	if err != nil {
 		log.Fatalln("fail to write sk: " + err.Error())
 	}
 	defer f.Close()
-	_, err = f.Write(PrivateKeyToBytes(sk))
+	_, err = f.Write(data)
 	if err != nil {
 		log.Fatalln("fail to write sk: " + err.Error())
 	}
@@ -35,11 +47,14 @@ func WritePrivateKeyToFile(sk *rsa.PrivateKey, path string) {
 
 // Read key from the given path. If the key is invalid, exit the execution
 // because there is no need to continue.
-func ReadKeyFromPath(path string) *rsa.PrivateKey {
+func ReadKeyFromPath(path string) PrivateKey {
 	data, err := ioutil.ReadFile(path)
 	if err != nil || len(data) == 0 {
 		log.Fatalln("fail to read private key from path: " + path)
 	}
-	sk := BytesToPrivateKey(data)
+	sk, err := ParsePrivateKey(data)
+	if err != nil {
+		log.Fatalln("fail to parse private key from path: " + path + " err: " + err.Error())
+	}
 	return sk
 }
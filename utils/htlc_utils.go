@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/Luismorlan/btc_in_go/address"
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"google.golang.org/protobuf/proto"
+)
+
+// Return a random secret of script.PREIMAGE_SIZE bytes for a hash time locked contract,
+// and its SHA256 which the contract is locked with.
+func NewHTLCSecret() ([]byte, []byte, error) {
+	preimage := make([]byte, script.PREIMAGE_SIZE)
+	if _, err := rand.Read(preimage); err != nil {
+		return nil, nil, err
+	}
+	hash := sha256.Sum256(preimage)
+	return preimage, hash[:], nil
+}
+
+// Return a redeem script of a hash time locked contract. The recipient address can spend
+// it by revealing the secret whose SHA256 is hash, and the refund address from lockTime on,
+// a height or a time like the lock time of transactions. Both must be addresses of public
+// keys. Outputs pay to its hash, see GetScriptAddress.
+func CreateHTLCScript(hash []byte, recipient string, lockTime int64, refund string) ([]byte, error) {
+	if len(hash) != sha256.Size {
+		return nil, fmt.Errorf("hash must be %d bytes, got %d", sha256.Size, len(hash))
+	}
+	if lockTime <= 0 {
+		return nil, fmt.Errorf("lock time must be positive, got %d", lockTime)
+	}
+	recipientHash, err := decodeKeyAddress(recipient)
+	if err != nil {
+		return nil, err
+	}
+	refundHash, err := decodeKeyAddress(refund)
+	if err != nil {
+		return nil, err
+	}
+	return script.HashTimeLock(hash, recipientHash, lockTime, refundHash), nil
+}
+
+// Return the public key hash of an address paying to a public key.
+func decodeKeyAddress(addr string) ([]byte, error) {
+	version, hash, err := address.Decode(addr)
+	if err != nil {
+		return nil, err
+	}
+	if version != address.VERSION_P2PKH {
+		return nil, fmt.Errorf("%s is not the address of a public key", addr)
+	}
+	return hash, nil
+}
+
+// Create a transaction spending the output at utxo, which pays to the hash of the HTLC
+// redeem script, to the key of sk, paying feeRate base units per FEE_RATE_BYTES bytes.
+// With preimage, the recipient claims it. Without, the refund key takes it back, and the
+// transaction is locked until the lock time of the contract.
+// READONLY:
+// * spent
+func CreateHTLCSpend(sk PrivateKey, utxo model.UTXOLite, spent *model.Output, redeem []byte, preimage []byte, feeRate int64) (*model.Transaction, error) {
+	hash, recipient, lockTime, refund, ok := script.ExtractHashTimeLock(redeem)
+	if !ok {
+		return nil, errors.New("redeem script is not a hash time locked contract")
+	}
+	if !bytes.Equal(GetLockingScript(spent), script.PayToScriptHash(HashPublicKey(redeem))) {
+		return nil, errors.New("output is not paid to the redeem script")
+	}
+	pk := EncodePublicKey(sk.Public())
+	tx := &model.Transaction{
+		Inputs:  []*model.Input{{PrevTxHash: utxo.PrevTxHash, Index: utxo.Index}},
+		Outputs: []*model.Output{NewOutput(spent.Value, sk.Public())},
+		Version: TX_VERSION,
+	}
+	// What the unlocking script pushes after the signature and the public key.
+	var branch []byte
+	if preimage != nil {
+		if !bytes.Equal(HashPublicKey(pk), recipient) {
+			return nil, errors.New("the key is not the recipient of the contract")
+		}
+		if h := sha256.Sum256(preimage); len(preimage) != script.PREIMAGE_SIZE || !bytes.Equal(h[:], hash) {
+			return nil, errors.New("preimage doesn't match the hash of the contract")
+		}
+		branch = script.AppendInt(script.AppendData(nil, preimage), 1)
+	} else {
+		if !bytes.Equal(HashPublicKey(pk), refund) {
+			return nil, errors.New("the key is not the refund key of the contract")
+		}
+		tx.LockTime = lockTime
+		branch = script.AppendInt(nil, 0)
+	}
+
+	sign := func() error {
+		sig, err := CreateSignature(sk, tx, 0, spent, SIGHASH_ALL)
+		if err != nil {
+			return err
+		}
+		unlocking := script.AppendData(script.AppendData(nil, sig), pk)
+		tx.Inputs[0].Script = script.AppendData(append(unlocking, branch...), redeem)
+		return FillTxHash(tx)
+	}
+	// Sign once to learn the size, then pay the fee out of the output. The size never
+	// grows with a smaller output value.
+	if err := sign(); err != nil {
+		return nil, err
+	}
+	fee := GetFeeForSize(feeRate, int64(proto.Size(tx)))
+	if spent.Value <= fee {
+		return nil, fmt.Errorf("contract value %s doesn't cover fee %s", FormatAmount(spent.Value), FormatAmount(fee))
+	}
+	tx.Outputs[0].Value = spent.Value - fee
+	if err := sign(); err != nil {
+		return nil, err
+	}
+	if err := VerifyInput(tx, 0, spent); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Return the secret revealed by the recipient claiming the HTLC with the redeem script
+// in the transaction, or false if the transaction doesn't claim it.
+// READONLY:
+// * tx
+func ExtractHTLCPreimage(tx *model.Transaction, redeem []byte) ([]byte, bool) {
+	hash, _, _, _, ok := script.ExtractHashTimeLock(redeem)
+	if !ok {
+		return nil, false
+	}
+	for _, input := range tx.Inputs {
+		insts, err := script.Parse(GetUnlockingScript(input))
+		// Signature, public key, secret, 1 and the redeem script.
+		if err != nil || len(insts) != 5 || !bytes.Equal(insts[4].Data, redeem) {
+			continue
+		}
+		preimage := insts[2].Data
+		if h := sha256.Sum256(preimage); bytes.Equal(h[:], hash) {
+			return preimage, true
+		}
+	}
+	return nil, false
+}
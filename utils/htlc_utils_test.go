@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTLC(t *testing.T) {
+	alice, bob := createTestKey(t), createTestKey(t)
+	preimage, hash, err := NewHTLCSecret()
+	assert.Nil(t, err)
+	redeem, err := CreateHTLCScript(hash, GetAddress(bob.Public()), 20, GetAddress(alice.Public()))
+	assert.Nil(t, err)
+	_, err = CreateHTLCScript(hash, GetScriptAddress(redeem), 20, GetAddress(alice.Public()))
+	assert.NotNil(t, err)
+	_, err = CreateHTLCScript(hash[1:], GetAddress(bob.Public()), 20, GetAddress(alice.Public()))
+	assert.NotNil(t, err)
+
+	funding, err := NewAddressOutput(100000, GetScriptAddress(redeem))
+	assert.Nil(t, err)
+	cb := &model.Transaction{Outputs: []*model.Output{funding}, Version: TX_VERSION}
+	assert.Nil(t, FillTxHash(cb))
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(cb, l, nil, ValidationContext{})
+	utxo := model.UTXOLite{PrevTxHash: cb.Hash, Index: 0}
+
+	// Bob claims with the secret, which anyone can then read from the transaction.
+	claim, err := CreateHTLCSpend(bob, utxo, funding, redeem, preimage, 1000)
+	assert.Nil(t, err)
+	assert.Nil(t, IsValidTransaction(claim, l, ValidationContext{}))
+	assert.True(t, claim.Outputs[0].Value < funding.Value)
+	revealed, ok := ExtractHTLCPreimage(claim, redeem)
+	assert.True(t, ok)
+	assert.Equal(t, preimage, revealed)
+	_, err = CreateHTLCSpend(alice, utxo, funding, redeem, preimage, 1000)
+	assert.NotNil(t, err)
+	_, err = CreateHTLCSpend(bob, utxo, funding, redeem, hash, 1000)
+	assert.NotNil(t, err)
+
+	// Alice takes the coins back from the lock time on.
+	refund, err := CreateHTLCSpend(alice, utxo, funding, redeem, nil, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), refund.LockTime)
+	assert.NotNil(t, IsValidTransaction(refund, l, ValidationContext{Height: 19}))
+	assert.Nil(t, IsValidTransaction(refund, l, ValidationContext{Height: 20}))
+	_, ok = ExtractHTLCPreimage(refund, redeem)
+	assert.False(t, ok)
+	_, err = CreateHTLCSpend(bob, utxo, funding, redeem, nil, 1000)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+)
+
+// Length of RSA keys generated when no length is configured.
+const DEFAULT_RSA_LEN = 2048
+
+// PrivateKey signs transactions, whatever its signature scheme.
+type PrivateKey interface {
+	Type() model.KeyType
+	Public() PublicKey
+	// Sign the message, which is hashed by the scheme itself.
+	Sign(msg []byte) ([]byte, error)
+	// Size of signatures in bytes, without the signature hash type.
+	SignatureSize() int
+	// Encode the key in the PEM format to be stored in a file.
+	MarshalPEM() ([]byte, error)
+}
+
+// PublicKey verifies signatures, whatever its signature scheme.
+type PublicKey interface {
+	Type() model.KeyType
+	// Bytes of the key as they appear in transactions.
+	Bytes() []byte
+	Verify(msg []byte, sig []byte) bool
+	// Size of signatures by the key in bytes, without the signature hash type.
+	SignatureSize() int
+}
+
+type RSAPrivateKey struct {
+	Key *rsa.PrivateKey
+}
+
+func (k RSAPrivateKey) Type() model.KeyType {
+	return model.KeyType_RSA
+}
+
+func (k RSAPrivateKey) Public() PublicKey {
+	return RSAPublicKey{Key: &k.Key.PublicKey}
+}
+
+func (k RSAPrivateKey) Sign(msg []byte) ([]byte, error) {
+	return Sign(msg, k.Key)
+}
+
+func (k RSAPrivateKey) SignatureSize() int {
+	return k.Key.Size()
+}
+
+// RSA keys keep the PKCS #1 format they have always been stored in.
+func (k RSAPrivateKey) MarshalPEM() ([]byte, error) {
+	return PrivateKeyToBytes(k.Key), nil
+}
+
+type RSAPublicKey struct {
+	Key *rsa.PublicKey
+}
+
+func (k RSAPublicKey) Type() model.KeyType {
+	return model.KeyType_RSA
+}
+
+func (k RSAPublicKey) Bytes() []byte {
+	return PublicKeyToBytes(k.Key)
+}
+
+func (k RSAPublicKey) Verify(msg []byte, sig []byte) bool {
+	return Verify(msg, k.Key, sig)
+}
+
+func (k RSAPublicKey) SignatureSize() int {
+	return k.Key.Size()
+}
+
+type Ed25519PrivateKey struct {
+	Key ed25519.PrivateKey
+}
+
+func (k Ed25519PrivateKey) Type() model.KeyType {
+	return model.KeyType_ED25519
+}
+
+func (k Ed25519PrivateKey) Public() PublicKey {
+	return Ed25519PublicKey{Key: k.Key.Public().(ed25519.PublicKey)}
+}
+
+func (k Ed25519PrivateKey) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(k.Key, msg), nil
+}
+
+func (k Ed25519PrivateKey) SignatureSize() int {
+	return ed25519.SignatureSize
+}
+
+func (k Ed25519PrivateKey) MarshalPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+type Ed25519PublicKey struct {
+	Key ed25519.PublicKey
+}
+
+func (k Ed25519PublicKey) Type() model.KeyType {
+	return model.KeyType_ED25519
+}
+
+func (k Ed25519PublicKey) Bytes() []byte {
+	return k.Key
+}
+
+func (k Ed25519PublicKey) Verify(msg []byte, sig []byte) bool {
+	return ed25519.Verify(k.Key, msg, sig)
+}
+
+func (k Ed25519PublicKey) SignatureSize() int {
+	return ed25519.SignatureSize
+}
+
+// Return the key type of the name, e.g. "ed25519".
+func ParseKeyType(name string) (model.KeyType, error) {
+	t, exist := model.KeyType_value[strings.ToUpper(name)]
+	if !exist {
+		return 0, fmt.Errorf("unknown key type %q, must be rsa or ed25519", name)
+	}
+	return model.KeyType(t), nil
+}
+
+// Generate a new key of the type. rsaLen is the length of RSA keys, DEFAULT_RSA_LEN if
+// not positive.
+func GenerateKey(t model.KeyType, rsaLen int) (PrivateKey, error) {
+	switch t {
+	case model.KeyType_RSA:
+		if rsaLen <= 0 {
+			rsaLen = DEFAULT_RSA_LEN
+		}
+		sk, err := rsa.GenerateKey(rand.Reader, rsaLen)
+		if err != nil {
+			return nil, err
+		}
+		return RSAPrivateKey{Key: sk}, nil
+	case model.KeyType_ED25519:
+		_, sk, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return Ed25519PrivateKey{Key: sk}, nil
+	default:
+		return nil, fmt.Errorf("unknown key type: %d", t)
+	}
+}
+
+// Parse public key bytes of an output with the key type.
+func ParsePublicKey(t model.KeyType, b []byte) (PublicKey, error) {
+	switch t {
+	case model.KeyType_RSA:
+		pk := BytesToPublicKey(b)
+		if pk == nil {
+			return nil, errors.New("invalid bytes when reconstructing RSA public key")
+		}
+		return RSAPublicKey{Key: pk}, nil
+	case model.KeyType_ED25519:
+		if len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("ed25519 public key must be %d bytes, got %d", ed25519.PublicKeySize, len(b))
+		}
+		return Ed25519PublicKey{Key: ed25519.PublicKey(b)}, nil
+	default:
+		return nil, fmt.Errorf("unknown key type: %d", t)
+	}
+}
+
+// Encode the public key as it appears in scripts, 1 byte of key type followed by its bytes.
+func EncodePublicKey(pk PublicKey) []byte {
+	return append([]byte{byte(pk.Type())}, pk.Bytes()...)
+}
+
+// Decode a public key encoded by EncodePublicKey.
+func DecodePublicKey(b []byte) (PublicKey, error) {
+	if len(b) == 0 {
+		return nil, errors.New("encoded public key is empty")
+	}
+	return ParsePublicKey(model.KeyType(b[0]), b[1:])
+}
+
+// Return an output paying value to the hash of the public key.
+func NewOutput(value int64, pk PublicKey) *model.Output {
+	return &model.Output{
+		Value:  value,
+		Script: script.PayToPublicKeyHash(HashPublicKey(EncodePublicKey(pk))),
+	}
+}
+
+// Parse a private key in the PEM format. Both RSA keys in the PKCS #1 format, which is how
+// keys were stored before other key types existed, and any key in the PKCS #8 format are
+// accepted.
+func ParsePrivateKey(data []byte) (PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		sk := BytesToPrivateKey(data)
+		if sk == nil {
+			return nil, errors.New("invalid RSA private key")
+		}
+		return RSAPrivateKey{Key: sk}, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch sk := key.(type) {
+		case *rsa.PrivateKey:
+			return RSAPrivateKey{Key: sk}, nil
+		case ed25519.PrivateKey:
+			return Ed25519PrivateKey{Key: sk}, nil
+		default:
+			return nil, fmt.Errorf("unsupported private key: %T", key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Create an Ed25519 key, which is much faster to generate than RSA.
+func createTestKey(t *testing.T) PrivateKey {
+	sk, err := GenerateKey(model.KeyType_ED25519, 0)
+	assert.Nil(t, err)
+	return sk
+}
+
+func TestSignAndVerifyAllKeyTypes(t *testing.T) {
+	msg := []byte("Hello World!")
+	for _, keyType := range []model.KeyType{model.KeyType_RSA, model.KeyType_ED25519} {
+		sk, err := GenerateKey(keyType, KEY_BITS)
+		assert.Nil(t, err)
+		assert.Equal(t, keyType, sk.Type())
+		sig, err := sk.Sign(msg)
+		assert.Nil(t, err)
+		assert.Equal(t, sk.SignatureSize(), len(sig))
+
+		pk, err := ParsePublicKey(keyType, sk.Public().Bytes())
+		assert.Nil(t, err)
+		assert.True(t, pk.Verify(msg, sig))
+		assert.False(t, pk.Verify([]byte("Hello World?"), sig))
+
+		// Key files keep the key type.
+		data, err := sk.MarshalPEM()
+		assert.Nil(t, err)
+		parsed, err := ParsePrivateKey(data)
+		assert.Nil(t, err)
+		assert.Equal(t, sk, parsed)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	// Key files written before key types existed are RSA keys in the PKCS #1 format.
+	legacy, _ := GenerateKeyPair(KEY_BITS)
+	sk, err := ParsePrivateKey(PrivateKeyToBytes(legacy))
+	assert.Nil(t, err)
+	assert.Equal(t, RSAPrivateKey{Key: legacy}, sk)
+	_, err = ParsePrivateKey([]byte("not a key"))
+	assert.NotNil(t, err)
+
+	_, err = ParsePublicKey(model.KeyType_ED25519, []byte{1, 2, 3})
+	assert.NotNil(t, err)
+	_, err = ParsePublicKey(model.KeyType_RSA, []byte{1, 2, 3})
+	assert.NotNil(t, err)
+	_, err = ParsePublicKey(model.KeyType(5), []byte{1, 2, 3})
+	assert.NotNil(t, err)
+
+	keyType, err := ParseKeyType("ed25519")
+	assert.Nil(t, err)
+	assert.Equal(t, model.KeyType_ED25519, keyType)
+	_, err = ParseKeyType("dsa")
+	assert.NotNil(t, err)
+}
@@ -19,35 +19,74 @@ func CreateUtxoFromInput(input *model.Input) model.UTXO {
 // 3. Store every output.
 // Return true if currently handles the transactions, false if the transaction is invalid.
 // Note: ledger will be changed afterwards, please make a deep copy before passing in.
-func HandleTransaction(tx *model.Transaction, l *model.Ledger) error {
+// If undo is not nil, the change to ledger is recorded into it.
+func HandleTransaction(tx *model.Transaction, l *model.Ledger, undo *model.BlockUndo, ctx ValidationContext) error {
 	// First validate the transaction.
-	err := IsValidTransaction(tx, l)
+	err := IsValidTransaction(tx, l, ctx)
 	if err != nil {
 		return err
 	}
 
-	ProcessInputsAndOutputs(tx, l)
+	ProcessInputsAndOutputs(tx, l, undo, ctx)
 
 	return nil
 }
 
-func ProcessInputsAndOutputs(tx *model.Transaction, l *model.Ledger) {
+// Claim all inputs and store all outputs of the transaction into ledger. Outputs are
+// marked with the height and median time past of ctx, which is the block including the
+// transaction. If undo is not nil, the change to ledger is recorded into it.
+// MUTABLE:
+// * l
+// * undo
+func ProcessInputsAndOutputs(tx *model.Transaction, l *model.Ledger, undo *model.BlockUndo, ctx ValidationContext) {
 	// Claim every input
 	for i := 0; i < len(tx.Inputs); i++ {
 		input := tx.Inputs[i]
 		utxo := CreateUtxoFromInput(input)
-		delete(l.L, model.GetUtxoLite(&utxo))
+		utxoLite := model.GetUtxoLite(&utxo)
+		if undo != nil {
+			recordSpent(undo, model.UndoEntry{Utxo: utxoLite, Output: l.L[utxoLite], Meta: getUtxoMeta(l, utxoLite)})
+		}
+		delete(l.L, utxoLite)
+		delete(l.Meta, utxoLite)
 	}
 
 	// Store every output
+	meta := &model.UTXOMeta{Height: ctx.Height, Time: ctx.MedianTimePast, Coinbase: len(tx.Inputs) == 0}
 	for i := 0; i < len(tx.Outputs); i++ {
 		output := tx.Outputs[i]
 		utxo := model.UTXO{
 			PrevTxHash: tx.Hash,
 			Index:      int64(i),
 		}
-		l.L[model.GetUtxoLite(&utxo)] = output
+		utxoLite := model.GetUtxoLite(&utxo)
+		l.L[utxoLite] = output
+		l.Meta[utxoLite] = *meta
+		if undo != nil {
+			undo.Created = append(undo.Created, model.UndoEntry{Utxo: utxoLite, Output: output, Meta: meta})
+		}
+	}
+}
+
+// Return the metadata of the UTXO, or nil if it has none.
+func getUtxoMeta(l *model.Ledger, utxo model.UTXOLite) *model.UTXOMeta {
+	meta, exist := l.Meta[utxo]
+	if !exist {
+		return nil
+	}
+	return &meta
+}
+
+// Record a spent UTXO. If it was created by the same block, simply forget it, so that
+// spent and created UTXOs never overlap and can be applied in any order.
+func recordSpent(undo *model.BlockUndo, e model.UndoEntry) {
+	for i := 0; i < len(undo.Created); i++ {
+		if undo.Created[i].Utxo == e.Utxo {
+			undo.Created = append(undo.Created[:i], undo.Created[i+1:]...)
+			return
+		}
 	}
+	undo.Spent = append(undo.Spent, e)
 }
 
 // Handle a bunch of transactions.
@@ -55,11 +94,12 @@ func ProcessInputsAndOutputs(tx *model.Transaction, l *model.Ledger) {
 // When error, this function returns all transactions that causes error.
 // MUTABLE:
 // * l
-func HandleTransactions(txs []*model.Transaction, l *model.Ledger) ([]*model.Transaction, error) {
+// * undo
+func HandleTransactions(txs []*model.Transaction, l *model.Ledger, undo *model.BlockUndo, ctx ValidationContext) ([]*model.Transaction, error) {
 	errTxs := []*model.Transaction{}
 	for i := 0; i < len(txs); i++ {
 		tx := txs[i]
-		err := HandleTransaction(tx, l)
+		err := HandleTransaction(tx, l, undo, ctx)
 		if err != nil {
 			errTxs = append(errTxs, tx)
 		}
@@ -76,5 +116,64 @@ func GetLedgerDeepCopy(l *model.Ledger) *model.Ledger {
 	for k, v := range l.L {
 		res.L[k] = v
 	}
+	for k, v := range l.Meta {
+		res.Meta[k] = v
+	}
 	return res
 }
+
+// Roll back the ledger from a block to its parent.
+// MUTABLE:
+// * l
+func RollbackBlock(l *model.Ledger, undo *model.BlockUndo) {
+	for _, e := range undo.Created {
+		delete(l.L, e.Utxo)
+		delete(l.Meta, e.Utxo)
+	}
+	for _, e := range undo.Spent {
+		setUndoEntry(l, e)
+	}
+}
+
+// Roll forward the ledger from a block's parent to the block.
+// MUTABLE:
+// * l
+func RollforwardBlock(l *model.Ledger, undo *model.BlockUndo) {
+	for _, e := range undo.Spent {
+		delete(l.L, e.Utxo)
+		delete(l.Meta, e.Utxo)
+	}
+	for _, e := range undo.Created {
+		setUndoEntry(l, e)
+	}
+}
+
+// Put the UTXO of the undo entry into the ledger.
+func setUndoEntry(l *model.Ledger, e model.UndoEntry) {
+	l.L[e.Utxo] = e.Output
+	if e.Meta != nil {
+		l.Meta[e.Utxo] = *e.Meta
+	}
+}
+
+// Return the ledger at the given block, derived from the UTXO set at tail by rolling
+// back to the fork point and then forward to the block. The returned ledger is always
+// a copy that can be changed freely.
+// READONLY:
+// * chain
+func GetLedgerAtBlock(chain *model.Blockchain, bw *model.BlockWrapper) *model.Ledger {
+	l := GetLedgerDeepCopy(chain.UTXOSet)
+	fork := GetForkPoint(chain.Tail, bw)
+	for b := chain.Tail; b != fork; b = b.Parent {
+		RollbackBlock(l, b.Undo)
+	}
+	// Blocks from the fork point to the target block, in reverse order.
+	forward := []*model.BlockWrapper{}
+	for b := bw; b != fork; b = b.Parent {
+		forward = append(forward, b)
+	}
+	for i := len(forward) - 1; i >= 0; i-- {
+		RollforwardBlock(l, forward[i].Undo)
+	}
+	return l
+}
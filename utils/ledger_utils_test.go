@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestTx(hash string, inputs []model.UTXOLite, values ...int64) *model.Transaction {
+	tx := &model.Transaction{Hash: hash}
+	for _, in := range inputs {
+		tx.Inputs = append(tx.Inputs, &model.Input{PrevTxHash: in.PrevTxHash, Index: in.Index})
+	}
+	for _, v := range values {
+		tx.Outputs = append(tx.Outputs, &model.Output{Value: v})
+	}
+	return tx
+}
+
+// Apply transactions as a block on top of the ledger and return the undo record.
+func applyTestBlock(l *model.Ledger, txs ...*model.Transaction) *model.BlockUndo {
+	undo := &model.BlockUndo{}
+	for _, tx := range txs {
+		ProcessInputsAndOutputs(tx, l, undo, ValidationContext{})
+	}
+	return undo
+}
+
+func TestRollbackAndRollforward(t *testing.T) {
+	l := model.NewLedger()
+	applyTestBlock(l, createTestTx("aa", nil, 1, 2))
+	before := GetLedgerDeepCopy(l)
+
+	// "bb" spends an output of "aa", and "cc" spends an output created in the same block.
+	undo := applyTestBlock(l,
+		createTestTx("bb", []model.UTXOLite{{PrevTxHash: "aa", Index: 0}}, 1),
+		createTestTx("cc", []model.UTXOLite{{PrevTxHash: "bb", Index: 0}}, 1),
+	)
+	after := GetLedgerDeepCopy(l)
+	assert.Equal(t, 1, len(undo.Spent))
+	assert.Equal(t, 1, len(undo.Created))
+
+	RollbackBlock(l, undo)
+	assert.Equal(t, before.L, l.L)
+	RollforwardBlock(l, undo)
+	assert.Equal(t, after.L, l.L)
+}
+
+func TestGetLedgerAtBlock(t *testing.T) {
+	chain := model.NewBlockChain()
+	genesis := chain.Tail
+	addBlock := func(parent *model.BlockWrapper, l *model.Ledger, tx *model.Transaction) *model.BlockWrapper {
+		bw := &model.BlockWrapper{
+			B:      &model.Block{Hash: tx.Hash},
+			Parent: parent,
+			Height: parent.Height + 1,
+			Undo:   applyTestBlock(l, tx),
+		}
+		parent.Children = append(parent.Children, bw)
+		return bw
+	}
+
+	// Main chain: genesis -> a1 -> a2, the UTXO set is at a2.
+	a1 := addBlock(genesis, chain.UTXOSet, createTestTx("a1", nil, 1))
+	a2 := addBlock(a1, chain.UTXOSet, createTestTx("a2", []model.UTXOLite{{PrevTxHash: "a1", Index: 0}}, 1))
+	chain.Tail = a2
+
+	// Fork: genesis -> b1.
+	forkLedger := model.NewLedger()
+	b1 := addBlock(genesis, forkLedger, createTestTx("b1", nil, 5))
+
+	assert.Equal(t, forkLedger.L, GetLedgerAtBlock(chain, b1).L)
+	assert.Equal(t, 0, len(GetLedgerAtBlock(chain, genesis).L))
+	atA1 := GetLedgerAtBlock(chain, a1)
+	assert.Equal(t, 1, len(atA1.L))
+	assert.Contains(t, atA1.L, model.UTXOLite{PrevTxHash: "a1", Index: 0})
+	// The UTXO set itself is never changed.
+	assert.Contains(t, chain.UTXOSet.L, model.UTXOLite{PrevTxHash: "a2", Index: 0})
+	assert.Equal(t, genesis, GetForkPoint(a2, b1))
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/Luismorlan/btc_in_go/model"
+)
+
+// Lock times below this are block heights, others are unix timestamps in seconds.
+const LOCK_TIME_THRESHOLD = 500000000
+
+// With this flag set, the relative lock of an input sequence is a time in units of
+// 1 << SEQUENCE_GRANULARITY seconds, otherwise a number of blocks.
+const SEQUENCE_TYPE_FLAG = 1 << 22
+
+// Bits of an input sequence holding the relative lock, up to about 455 days or 65535 blocks.
+const SEQUENCE_MASK = 0xffff
+
+// Relative time locks are in units of 512 seconds.
+const SEQUENCE_GRANULARITY = 9
+
+// Return the sequence locking an input until the output spent is n blocks old.
+func RelativeLockBlocks(n int64) (int64, error) {
+	if n < 0 || n > SEQUENCE_MASK {
+		return 0, fmt.Errorf("relative lock of %d blocks is not between 0 and %d", n, SEQUENCE_MASK)
+	}
+	return n, nil
+}
+
+// Return the sequence locking an input until the output spent is at least the given
+// seconds old, rounded up to units of 512 seconds.
+func RelativeLockSeconds(seconds int64) (int64, error) {
+	units := (seconds + 1<<SEQUENCE_GRANULARITY - 1) >> SEQUENCE_GRANULARITY
+	if seconds < 0 || units > SEQUENCE_MASK {
+		return 0, fmt.Errorf("relative lock of %d seconds is not between 0 and %d", seconds, SEQUENCE_MASK<<SEQUENCE_GRANULARITY)
+	}
+	if units == 0 {
+		return 0, nil
+	}
+	return SEQUENCE_TYPE_FLAG | units, nil
+}
+
+// Whether the sequence is non-negative and only uses SEQUENCE_TYPE_FLAG and SEQUENCE_MASK.
+func IsValidSequence(sequence int64) bool {
+	return sequence >= 0 && sequence&^(SEQUENCE_TYPE_FLAG|SEQUENCE_MASK) == 0
+}
+
+// Check that the lock time of the transaction has passed in the context, i.e. the block
+// is at least at the lock height, or its median time past is at least the lock time.
+// READONLY:
+// * tx
+func CheckLockTime(tx *model.Transaction, ctx ValidationContext) error {
+	switch {
+	case tx.LockTime < 0:
+		return fmt.Errorf("negative lock time: %d", tx.LockTime)
+	case tx.LockTime < LOCK_TIME_THRESHOLD && tx.LockTime > ctx.Height:
+		return fmt.Errorf("transaction is locked until height %d, block height is %d", tx.LockTime, ctx.Height)
+	case tx.LockTime >= LOCK_TIME_THRESHOLD && tx.LockTime > ctx.MedianTimePast:
+		return fmt.Errorf("transaction is locked until time %d, median time past is %d", tx.LockTime, ctx.MedianTimePast)
+	}
+	return nil
+}
+
+// Check that the relative lock of every input has passed in the context, i.e. the output
+// spent is old enough by the height or the median time past before the block creating it.
+// Outputs not in a block yet, which have no metadata in the ledger, are as if created in
+// the context.
+// READONLY:
+// * tx
+// * l
+func CheckSequenceLocks(tx *model.Transaction, l *model.Ledger, ctx ValidationContext) error {
+	for i, input := range tx.Inputs {
+		if !IsValidSequence(input.Sequence) {
+			return fmt.Errorf("input %d has invalid sequence %#x", i, input.Sequence)
+		}
+		if input.Sequence == 0 {
+			continue
+		}
+		utxo := CreateUtxoFromInput(input)
+		meta, exist := l.Meta[model.GetUtxoLite(&utxo)]
+		if !exist {
+			meta = model.UTXOMeta{Height: ctx.Height, Time: ctx.MedianTimePast}
+		}
+		value := input.Sequence & SEQUENCE_MASK
+		if input.Sequence&SEQUENCE_TYPE_FLAG == 0 {
+			if ctx.Height-meta.Height < value {
+				return fmt.Errorf("input %d is locked until height %d, block height is %d", i, meta.Height+value, ctx.Height)
+			}
+		} else if ctx.MedianTimePast-meta.Time < value<<SEQUENCE_GRANULARITY {
+			return fmt.Errorf("input %d is locked until time %d, median time past is %d", i, meta.Time+value<<SEQUENCE_GRANULARITY, ctx.MedianTimePast)
+		}
+	}
+	return nil
+}
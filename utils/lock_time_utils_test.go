@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeLock(t *testing.T) {
+	seq, err := RelativeLockBlocks(10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), seq)
+	_, err = RelativeLockBlocks(SEQUENCE_MASK + 1)
+	assert.NotNil(t, err)
+
+	// Seconds are rounded up to units of 512 seconds.
+	seq, err = RelativeLockSeconds(513)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(SEQUENCE_TYPE_FLAG|2), seq)
+	seq, err = RelativeLockSeconds(0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), seq)
+	_, err = RelativeLockSeconds(-1)
+	assert.NotNil(t, err)
+
+	assert.True(t, IsValidSequence(SEQUENCE_TYPE_FLAG|SEQUENCE_MASK))
+	assert.False(t, IsValidSequence(SEQUENCE_MASK+1))
+	assert.False(t, IsValidSequence(-1))
+}
+
+func TestCheckLockTime(t *testing.T) {
+	tx := &model.Transaction{LockTime: 10}
+	assert.NotNil(t, CheckLockTime(tx, ValidationContext{Height: 9}))
+	assert.Nil(t, CheckLockTime(tx, ValidationContext{Height: 10}))
+
+	// Times are compared with median time past, not the height.
+	tx.LockTime = LOCK_TIME_THRESHOLD + 100
+	assert.NotNil(t, CheckLockTime(tx, ValidationContext{Height: LOCK_TIME_THRESHOLD + 100, MedianTimePast: LOCK_TIME_THRESHOLD + 99}))
+	assert.Nil(t, CheckLockTime(tx, ValidationContext{MedianTimePast: LOCK_TIME_THRESHOLD + 100}))
+
+	tx.LockTime = -1
+	assert.NotNil(t, CheckLockTime(tx, ValidationContext{Height: 10}))
+}
+
+func TestVesting(t *testing.T) {
+	sk := createTestKey(t)
+	pk := EncodePublicKey(sk.Public())
+	// The output can be spent by the key 5 blocks after it is created.
+	locking := append(script.AppendInt(nil, 5), script.OP_CHECKSEQUENCEVERIFY, script.OP_DROP)
+	locking = append(script.AppendData(locking, pk), script.OP_CHECKSIG)
+	funding := &model.Transaction{Outputs: []*model.Output{{Value: 100, Script: locking}}, Version: TX_VERSION}
+	assert.Nil(t, FillTxHash(funding))
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(funding, l, nil, ValidationContext{Height: 10, MedianTimePast: 1000})
+
+	spend := func(sequence int64) *model.Transaction {
+		tx := &model.Transaction{
+			Inputs:  []*model.Input{{PrevTxHash: funding.Hash, Index: 0, Sequence: sequence}},
+			Outputs: []*model.Output{NewOutput(90, sk.Public())},
+			Version: TX_VERSION,
+		}
+		sig, err := CreateSignature(sk, tx, 0, funding.Outputs[0], SIGHASH_ALL)
+		assert.Nil(t, err)
+		tx.Inputs[0].Script = script.AppendData(nil, sig)
+		assert.Nil(t, FillTxHash(tx))
+		return tx
+	}
+	tx := spend(5)
+	assert.NotNil(t, IsValidTransaction(tx, l, ValidationContext{Height: 14}))
+	assert.Nil(t, IsValidTransaction(tx, l, ValidationContext{Height: 15}))
+	// The script requires the input to be locked, and for blocks rather than time.
+	assert.NotNil(t, IsValidTransaction(spend(4), l, ValidationContext{Height: 15}))
+	assert.NotNil(t, IsValidTransaction(spend(SEQUENCE_TYPE_FLAG|5), l, ValidationContext{Height: 15, MedianTimePast: 1000 + 5<<SEQUENCE_GRANULARITY}))
+
+	// Time locks count from the median time past before the output was created.
+	seq, err := RelativeLockSeconds(1024)
+	assert.Nil(t, err)
+	assert.NotNil(t, CheckSequenceLocks(spend(seq), l, ValidationContext{Height: 20, MedianTimePast: 2023}))
+	assert.Nil(t, CheckSequenceLocks(spend(seq), l, ValidationContext{Height: 20, MedianTimePast: 2024}))
+
+	// Outputs not in a block yet can't be spent with a relative lock.
+	delete(l.Meta, model.UTXOLite{PrevTxHash: funding.Hash, Index: 0})
+	assert.NotNil(t, CheckSequenceLocks(tx, l, ValidationContext{Height: 100}))
+	assert.Nil(t, CheckSequenceLocks(spend(0), l, ValidationContext{Height: 100}))
+}
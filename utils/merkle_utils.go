@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Luismorlan/btc_in_go/model"
+)
+
+// Return hashes of all transactions in the block, coinbase comes first. These are the
+// leaves of the block's merkle tree.
+// READONLY:
+// * block
+func GetBlockTxHashes(block *model.Block) []string {
+	hashes := []string{block.GetCoinbase().GetHash()}
+	for i := 0; i < len(block.Txs); i++ {
+		hashes = append(hashes, block.Txs[i].Hash)
+	}
+	return hashes
+}
+
+// Return witness hashes of all transactions in the block, coinbase comes first. These are
+// the leaves of the block's witness merkle tree.
+// READONLY:
+// * block
+func GetBlockWitnessHashes(block *model.Block) ([]string, error) {
+	txs := append([]*model.Transaction{block.GetCoinbase()}, block.Txs...)
+	hashes := []string{}
+	for _, tx := range txs {
+		if tx == nil {
+			return nil, errors.New("block contains a nil transaction")
+		}
+		hash, err := GetWitnessHash(tx)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// Compute the merkle root and the witness merkle root of the block.
+// READONLY:
+// * block
+func ComputeBlockRoots(block *model.Block) (string, string, error) {
+	merkleRoot, err := ComputeMerkleRoot(GetBlockTxHashes(block))
+	if err != nil {
+		return "", "", err
+	}
+	witnessHashes, err := GetBlockWitnessHashes(block)
+	if err != nil {
+		return "", "", err
+	}
+	witnessRoot, err := ComputeMerkleRoot(witnessHashes)
+	if err != nil {
+		return "", "", err
+	}
+	return merkleRoot, witnessRoot, nil
+}
+
+// Hash 2 child nodes into their parent node.
+func hashMerkleNode(left []byte, right []byte) []byte {
+	return SHA256(append(append([]byte{}, left...), right...))
+}
+
+// Compute one level up in the merkle tree. The last node is paired with itself if the
+// level has odd number of nodes.
+func nextMerkleLevel(level [][]byte) [][]byte {
+	next := [][]byte{}
+	for i := 0; i < len(level); i += 2 {
+		right := level[i]
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+		next = append(next, hashMerkleNode(level[i], right))
+	}
+	return next
+}
+
+func hexesToBytes(hashes []string) ([][]byte, error) {
+	res := [][]byte{}
+	for _, h := range hashes {
+		b, err := HexToBytes(h)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, nil
+}
+
+// Compute the merkle root of the given hex hashes.
+func ComputeMerkleRoot(hashes []string) (string, error) {
+	if len(hashes) == 0 {
+		return "", errors.New("cannot compute merkle root of nothing")
+	}
+	level, err := hexesToBytes(hashes)
+	if err != nil {
+		return "", err
+	}
+	for len(level) > 1 {
+		level = nextMerkleLevel(level)
+	}
+	return BytesToHex(level[0]), nil
+}
+
+// Return the merkle branch of the leaf at index, which are the sibling hashes from the
+// leaf level up to right below the root. Together with the leaf and its index they are
+// enough to prove the leaf is included under the merkle root.
+func GetMerkleBranch(hashes []string, index int) ([]string, error) {
+	if index < 0 || index >= len(hashes) {
+		return nil, fmt.Errorf("merkle leaf index out of range: %d", index)
+	}
+	level, err := hexesToBytes(hashes)
+	if err != nil {
+		return nil, err
+	}
+	branch := []string{}
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		branch = append(branch, BytesToHex(level[sibling]))
+		level = nextMerkleLevel(level)
+		index /= 2
+	}
+	return branch, nil
+}
+
+// Verify the leaf at index is included under the merkle root using the merkle branch.
+func VerifyMerkleBranch(leaf string, branch []string, index int, root string) bool {
+	node, err := HexToBytes(leaf)
+	if err != nil {
+		return false
+	}
+	for _, h := range branch {
+		sibling, err := HexToBytes(h)
+		if err != nil {
+			return false
+		}
+		if index%2 == 0 {
+			node = hashMerkleNode(node, sibling)
+		} else {
+			node = hashMerkleNode(sibling, node)
+		}
+		index /= 2
+	}
+	return BytesToHex(node) == root
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestHashes(n int) []string {
+	hashes := []string{}
+	for i := 0; i < n; i++ {
+		hashes = append(hashes, BytesToHex(SHA256([]byte{byte(i)})))
+	}
+	return hashes
+}
+
+func TestComputeMerkleRoot(t *testing.T) {
+	hashes := createTestHashes(3)
+	leaves, _ := hexesToBytes(hashes)
+	left := hashMerkleNode(leaves[0], leaves[1])
+	right := hashMerkleNode(leaves[2], leaves[2])
+	expected := BytesToHex(hashMerkleNode(left, right))
+
+	root, err := ComputeMerkleRoot(hashes)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, root)
+
+	root, _ = ComputeMerkleRoot(hashes[:1])
+	assert.Equal(t, hashes[0], root)
+
+	_, err = ComputeMerkleRoot([]string{})
+	assert.NotNil(t, err)
+}
+
+func TestMerkleBranch(t *testing.T) {
+	for n := 1; n <= 7; n++ {
+		hashes := createTestHashes(n)
+		root, _ := ComputeMerkleRoot(hashes)
+		for i := 0; i < n; i++ {
+			branch, err := GetMerkleBranch(hashes, i)
+			assert.Nil(t, err)
+			assert.True(t, VerifyMerkleBranch(hashes[i], branch, i, root))
+			// A branch doesn't prove any other leaf.
+			assert.False(t, VerifyMerkleBranch(BytesToHex(SHA256([]byte("x"))), branch, i, root))
+		}
+	}
+	_, err := GetMerkleBranch(createTestHashes(2), 2)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"google.golang.org/protobuf/proto"
+)
+
+// Return a redeem script requiring m signatures by different ones of the public keys,
+// which are encoded by EncodePublicKey. Outputs pay to its hash, see GetScriptAddress.
+func CreateMultiSigScript(m int, pks [][]byte) ([]byte, error) {
+	if len(pks) == 0 || len(pks) > script.MAX_MULTISIG_KEYS {
+		return nil, fmt.Errorf("number of public keys must be between 1 and %d, got %d", script.MAX_MULTISIG_KEYS, len(pks))
+	}
+	if m < 1 || m > len(pks) {
+		return nil, fmt.Errorf("number of signatures must be between 1 and %d, got %d", len(pks), m)
+	}
+	seen := make(map[string]bool)
+	for i, pk := range pks {
+		if _, err := DecodePublicKey(pk); err != nil {
+			return nil, fmt.Errorf("public key %d: %s", i, err)
+		}
+		if seen[string(pk)] {
+			return nil, fmt.Errorf("public key %d appears more than once", i)
+		}
+		seen[string(pk)] = true
+	}
+	redeem := script.MultiSig(m, pks)
+	// The unlocking script must be able to push the redeem script.
+	if len(redeem) > script.MAX_ELEMENT_SIZE {
+		return nil, fmt.Errorf("redeem script of %d bytes is larger than %d bytes, use fewer or smaller keys", len(redeem), script.MAX_ELEMENT_SIZE)
+	}
+	return redeem, nil
+}
+
+// Return an unlocking script at least as large as any spending an output paying to the
+// hash of the multisig redeem script.
+func estimateMultiSigUnlocking(redeem []byte) ([]byte, error) {
+	m, pks, ok := script.ExtractMultiSig(redeem)
+	if !ok {
+		return nil, errors.New("redeem script is not a multisig script")
+	}
+	size := 0
+	for _, pk := range pks {
+		key, err := DecodePublicKey(pk)
+		if err != nil {
+			return nil, err
+		}
+		if key.SignatureSize()+1 > size {
+			size = key.SignatureSize() + 1
+		}
+	}
+	var unlocking []byte
+	for i := 0; i < m; i++ {
+		unlocking = script.AppendData(unlocking, make([]byte, size))
+	}
+	return script.AppendData(unlocking, redeem), nil
+}
+
+// Create a partially signed transaction of tx without any signature. The input at each
+// index spends the output in spent, which pays to the hash of the multisig redeem script.
+// READONLY:
+// * tx
+// * spent
+func NewPSBT(tx *model.Transaction, spent []*model.Output, redeems [][]byte) (*model.PartiallySignedTransaction, error) {
+	if len(spent) != len(tx.Inputs) || len(redeems) != len(tx.Inputs) {
+		return nil, fmt.Errorf("transaction has %d inputs, got %d spent outputs and %d redeem scripts", len(tx.Inputs), len(spent), len(redeems))
+	}
+	p := &model.PartiallySignedTransaction{Tx: proto.Clone(tx).(*model.Transaction)}
+	for i := range tx.Inputs {
+		p.Tx.Inputs[i].Signature = nil
+		p.Tx.Inputs[i].Script = nil
+		p.Inputs = append(p.Inputs, &model.PartialInput{
+			Spent:        proto.Clone(spent[i]).(*model.Output),
+			RedeemScript: redeems[i],
+		})
+	}
+	if err := checkPSBT(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Check that p is well formed, so that co-signers know what they sign.
+// READONLY:
+// * p
+func checkPSBT(p *model.PartiallySignedTransaction) error {
+	if p.Tx == nil {
+		return errors.New("partially signed transaction has no transaction")
+	}
+	if len(p.Inputs) != len(p.Tx.Inputs) {
+		return fmt.Errorf("transaction has %d inputs, got %d partial inputs", len(p.Tx.Inputs), len(p.Inputs))
+	}
+	hash, err := GetTxHash(p.Tx)
+	if err != nil {
+		return err
+	}
+	if hash != p.Tx.Hash {
+		return errors.New("transaction hash doesn't match the transaction")
+	}
+	for i, input := range p.Inputs {
+		if input.Spent == nil {
+			return fmt.Errorf("input %d has no spent output", i)
+		}
+		if _, _, ok := script.ExtractMultiSig(input.RedeemScript); !ok {
+			return fmt.Errorf("input %d has no multisig redeem script", i)
+		}
+		if !bytes.Equal(GetLockingScript(input.Spent), script.PayToScriptHash(HashPublicKey(input.RedeemScript))) {
+			return fmt.Errorf("input %d spends an output not paid to its redeem script", i)
+		}
+	}
+	return nil
+}
+
+// Add the signature to the input, replacing an earlier one by the same key.
+// MUTABLE:
+// * input
+func addPartialSignature(input *model.PartialInput, sig *model.PartialSignature) {
+	for i, s := range input.Signatures {
+		if bytes.Equal(s.PublicKey, sig.PublicKey) {
+			input.Signatures[i] = sig
+			return
+		}
+	}
+	input.Signatures = append(input.Signatures, sig)
+}
+
+// Sign every input whose redeem script has the public key of sk with SIGHASH_ALL.
+// Return the number of inputs signed, which is never 0 without an error.
+// MUTABLE:
+// * p
+func SignPSBT(sk PrivateKey, p *model.PartiallySignedTransaction) (int, error) {
+	if err := checkPSBT(p); err != nil {
+		return 0, err
+	}
+	pk := EncodePublicKey(sk.Public())
+	signed := 0
+	for i, input := range p.Inputs {
+		_, pks, _ := script.ExtractMultiSig(input.RedeemScript)
+		for _, key := range pks {
+			if !bytes.Equal(key, pk) {
+				continue
+			}
+			sig, err := CreateSignature(sk, p.Tx, i, input.Spent, SIGHASH_ALL)
+			if err != nil {
+				return 0, err
+			}
+			addPartialSignature(input, &model.PartialSignature{PublicKey: pk, Signature: sig})
+			signed++
+		}
+	}
+	if signed == 0 {
+		return 0, errors.New("the key is not in the redeem script of any input")
+	}
+	return signed, nil
+}
+
+// Add signatures of other, which must be for the same transaction and outputs spent, to
+// p. Signatures are verified before they are added.
+// MUTABLE:
+// * p
+// READONLY:
+// * other
+func CombinePSBT(p *model.PartiallySignedTransaction, other *model.PartiallySignedTransaction) error {
+	if err := checkPSBT(p); err != nil {
+		return err
+	}
+	if err := checkPSBT(other); err != nil {
+		return err
+	}
+	if !proto.Equal(p.Tx, other.Tx) {
+		return fmt.Errorf("transactions %s and %s differ", p.Tx.Hash, other.Tx.Hash)
+	}
+	for i, input := range other.Inputs {
+		if !proto.Equal(input.Spent, p.Inputs[i].Spent) || !bytes.Equal(input.RedeemScript, p.Inputs[i].RedeemScript) {
+			return fmt.Errorf("input %d spends different outputs", i)
+		}
+		checker := txChecker{tx: p.Tx, index: i, spent: input.Spent}
+		for _, sig := range input.Signatures {
+			if !checker.CheckSig(sig.Signature, sig.PublicKey) {
+				return fmt.Errorf("input %d has an invalid signature", i)
+			}
+			addPartialSignature(p.Inputs[i], proto.Clone(sig).(*model.PartialSignature))
+		}
+	}
+	return nil
+}
+
+// Return the transaction of p with unlocking scripts, which push the signatures required
+// by each redeem script in the order of its keys, followed by the redeem script. Fail if
+// any input doesn't have enough valid signatures.
+// READONLY:
+// * p
+func FinalizePSBT(p *model.PartiallySignedTransaction) (*model.Transaction, error) {
+	if err := checkPSBT(p); err != nil {
+		return nil, err
+	}
+	tx := proto.Clone(p.Tx).(*model.Transaction)
+	for i, input := range p.Inputs {
+		m, pks, _ := script.ExtractMultiSig(input.RedeemScript)
+		checker := txChecker{tx: tx, index: i, spent: input.Spent}
+		var unlocking []byte
+		count := 0
+		for _, pk := range pks {
+			for _, sig := range input.Signatures {
+				if count < m && bytes.Equal(sig.PublicKey, pk) && checker.CheckSig(sig.Signature, pk) {
+					unlocking = script.AppendData(unlocking, sig.Signature)
+					count++
+				}
+			}
+		}
+		if count < m {
+			return nil, fmt.Errorf("input %d has %d of %d signatures required", i, count, m)
+		}
+		tx.Inputs[i].Script = script.AppendData(unlocking, input.RedeemScript)
+		if err := VerifyInput(tx, i, input.Spent); err != nil {
+			return nil, fmt.Errorf("input %d: %s", i, err)
+		}
+	}
+	return tx, nil
+}
+
+// Write the partially signed transaction to the file at path, which co-signers import
+// with ReadPSBTFile.
+func WritePSBTFile(p *model.PartiallySignedTransaction, path string) error {
+	data, err := proto.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Read a partially signed transaction written by WritePSBTFile.
+func ReadPSBTFile(path string) (*model.PartiallySignedTransaction, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &model.PartiallySignedTransaction{}
+	if err := proto.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("%s is not a partially signed transaction: %s", path, err)
+	}
+	if err := checkPSBT(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
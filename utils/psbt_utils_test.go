@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCreateMultiSigScript(t *testing.T) {
+	a, b := EncodePublicKey(createTestKey(t).Public()), EncodePublicKey(createTestKey(t).Public())
+	redeem, err := CreateMultiSigScript(2, [][]byte{a, b})
+	assert.Nil(t, err)
+	output, err := NewAddressOutput(10, GetScriptAddress(redeem))
+	assert.Nil(t, err)
+	addr, ok := GetOutputAddress(output)
+	assert.True(t, ok)
+	assert.Equal(t, GetScriptAddress(redeem), addr)
+
+	_, err = CreateMultiSigScript(3, [][]byte{a, b})
+	assert.NotNil(t, err)
+	_, err = CreateMultiSigScript(1, [][]byte{a, a})
+	assert.NotNil(t, err)
+	_, err = CreateMultiSigScript(1, [][]byte{a[1:]})
+	assert.NotNil(t, err)
+}
+
+func TestMultiSigPSBT(t *testing.T) {
+	keys := []PrivateKey{createTestKey(t), createTestKey(t), createTestKey(t)}
+	pks := [][]byte{}
+	for _, sk := range keys {
+		pks = append(pks, EncodePublicKey(sk.Public()))
+	}
+	redeem, err := CreateMultiSigScript(2, pks)
+	assert.Nil(t, err)
+	funding, err := NewAddressOutput(1000, GetScriptAddress(redeem))
+	assert.Nil(t, err)
+	cb := &model.Transaction{Outputs: []*model.Output{funding}, Version: TX_VERSION}
+	assert.Nil(t, FillTxHash(cb))
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(cb, l, nil, ValidationContext{})
+	utxos := map[model.UTXOLite]*model.Output{{PrevTxHash: cb.Hash, Index: 0}: funding}
+
+	tx, err := CreateUnsignedTransaction(utxos, []*model.Output{NewOutput(600, keys[0].Public())}, 100, funding)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(300), tx.Outputs[1].Value)
+	p, err := NewPSBT(tx, []*model.Output{funding}, [][]byte{redeem})
+	assert.Nil(t, err)
+
+	// Each co-signer signs a copy passed as a file.
+	path := filepath.Join(t.TempDir(), "tx.psbt")
+	assert.Nil(t, WritePSBTFile(p, path))
+	n, err := SignPSBT(keys[0], p)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	_, err = FinalizePSBT(p)
+	assert.NotNil(t, err)
+	_, err = SignPSBT(createTestKey(t), p)
+	assert.NotNil(t, err)
+
+	other, err := ReadPSBTFile(path)
+	assert.Nil(t, err)
+	_, err = SignPSBT(keys[2], other)
+	assert.Nil(t, err)
+	assert.Nil(t, CombinePSBT(p, other))
+	final, err := FinalizePSBT(p)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Hash, final.Hash)
+	assert.Nil(t, IsValidTransaction(final, l, ValidationContext{}))
+
+	// Signatures for another transaction are not combined.
+	changed := proto.Clone(other).(*model.PartiallySignedTransaction)
+	changed.Tx.Outputs[0].Value = 700
+	assert.Nil(t, FillTxHash(changed.Tx))
+	assert.NotNil(t, CombinePSBT(p, changed))
+	changed = proto.Clone(other).(*model.PartiallySignedTransaction)
+	changed.Inputs[0].Signatures[0].Signature[0] ^= 1
+	assert.NotNil(t, CombinePSBT(p, changed))
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+)
+
+// Return the locking script of the output. Outputs written before locking scripts existed
+// pay to their public key.
+// READONLY:
+// * output
+func GetLockingScript(output *model.Output) []byte {
+	if len(output.Script) > 0 {
+		return output.Script
+	}
+	if len(output.PublicKey) > 0 {
+		return script.PayToPublicKey(append([]byte{byte(output.KeyType)}, output.PublicKey...))
+	}
+	return nil
+}
+
+// Return the unlocking script of the input. Inputs written before unlocking scripts
+// existed push their signature.
+// READONLY:
+// * input
+func GetUnlockingScript(input *model.Input) []byte {
+	if len(input.Script) > 0 {
+		return input.Script
+	}
+	if len(input.Signature) > 0 {
+		return script.AppendData(nil, input.Signature)
+	}
+	return nil
+}
+
+// Checks signatures, lock times and sequences of scripts for the input at index of tx, which spends
+// the spent output.
+type txChecker struct {
+	tx    *model.Transaction
+	index int
+	spent *model.Output
+}
+
+func (c txChecker) CheckSig(sig []byte, pk []byte) bool {
+	s, hashType, err := SplitSignature(sig)
+	if err != nil {
+		return false
+	}
+	key, err := DecodePublicKey(pk)
+	if err != nil {
+		return false
+	}
+	data, err := GetSignatureData(c.tx, c.index, c.spent, hashType)
+	if err != nil {
+		return false
+	}
+	return key.Verify(data, s)
+}
+
+// The lock time of the transaction must be of the same kind, a height or a time, and not
+// earlier.
+func (c txChecker) CheckLockTime(lockTime int64) bool {
+	if (lockTime < LOCK_TIME_THRESHOLD) != (c.tx.LockTime < LOCK_TIME_THRESHOLD) {
+		return false
+	}
+	return lockTime <= c.tx.LockTime
+}
+
+// The sequence of the input must be a relative lock of the same kind, blocks or time, and
+// not shorter.
+func (c txChecker) CheckSequence(sequence int64) bool {
+	own := c.tx.Inputs[c.index].Sequence
+	if !IsValidSequence(sequence) || !IsValidSequence(own) {
+		return false
+	}
+	if sequence&SEQUENCE_TYPE_FLAG != own&SEQUENCE_TYPE_FLAG {
+		return false
+	}
+	return sequence&SEQUENCE_MASK <= own&SEQUENCE_MASK
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendScript(t *testing.T) {
+	alice, bob := createTestKey(t), createTestKey(t)
+	// Either key can spend after height 10.
+	locking := append(script.AppendInt(nil, 10), script.OP_CHECKLOCKTIMEVERIFY, script.OP_DROP)
+	locking = script.AppendInt(locking, 1)
+	locking = script.AppendData(locking, EncodePublicKey(alice.Public()))
+	locking = script.AppendData(locking, EncodePublicKey(bob.Public()))
+	locking = append(script.AppendInt(locking, 2), script.OP_CHECKMULTISIG)
+	funding := &model.Transaction{Outputs: []*model.Output{{Value: 100, Script: locking}}, Version: TX_VERSION}
+	assert.Nil(t, FillTxHash(funding))
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(funding, l, nil, ValidationContext{})
+
+	tx := &model.Transaction{
+		Inputs:   []*model.Input{{PrevTxHash: funding.Hash, Index: 0}},
+		Outputs:  []*model.Output{NewOutput(90, bob.Public())},
+		Version:  TX_VERSION,
+		LockTime: 10,
+	}
+	sig, err := CreateSignature(bob, tx, 0, funding.Outputs[0], SIGHASH_ALL)
+	assert.Nil(t, err)
+	tx.Inputs[0].Script = script.AppendData(nil, sig)
+	assert.Nil(t, FillTxHash(tx))
+	assert.Nil(t, IsValidTransaction(tx, l, ValidationContext{Height: 10}))
+
+	// SignInput only knows scripts paying to a single key.
+	assert.NotNil(t, SignInput(bob, tx, 0, funding.Outputs[0], SIGHASH_ALL))
+
+	// The lock time is signed, and a time doesn't satisfy a height.
+	for _, lockTime := range []int64{9, LOCK_TIME_THRESHOLD + 10} {
+		tx.LockTime = lockTime
+		sig, err = CreateSignature(bob, tx, 0, funding.Outputs[0], SIGHASH_ALL)
+		assert.Nil(t, err)
+		tx.Inputs[0].Script = script.AppendData(nil, sig)
+		assert.Nil(t, FillTxHash(tx))
+		assert.NotNil(t, IsValidTransaction(tx, l, ValidationContext{Height: 10}))
+	}
+
+	// Outputs must have a locking script.
+	tx.LockTime = 10
+	tx.Outputs[0] = &model.Output{Value: 90}
+	sig, err = CreateSignature(alice, tx, 0, funding.Outputs[0], SIGHASH_ALL)
+	assert.Nil(t, err)
+	tx.Inputs[0].Script = script.AppendData(nil, sig)
+	assert.Nil(t, FillTxHash(tx))
+	assert.Contains(t, IsValidTransaction(tx, l, ValidationContext{Height: 10}).Error(), "locking script")
+}
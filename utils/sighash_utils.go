@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+)
+
+// Version of the transaction format we produce.
+const TX_VERSION = 1
+
+// Signature hash types, the last byte of every signature. The lower bits decide which
+// outputs are signed, and SIGHASH_ANYONECANPAY signs the own input only so that others
+// can add inputs.
+const (
+	// Sign all outputs.
+	SIGHASH_ALL = 0x01
+	// Sign no output, anyone can decide where the coins go.
+	SIGHASH_NONE = 0x02
+	// Sign only the output at the same index as the input.
+	SIGHASH_SINGLE = 0x03
+	// Sign only the own input.
+	SIGHASH_ANYONECANPAY = 0x80
+)
+
+// Whether the signature hash type is one of the defined ones.
+func IsValidSigHashType(hashType byte) bool {
+	base := hashType &^ SIGHASH_ANYONECANPAY
+	return base == SIGHASH_ALL || base == SIGHASH_NONE || base == SIGHASH_SINGLE
+}
+
+// Append b prefixed with its length, so that concatenated fields are never ambiguous.
+func appendWithLength(data []byte, b []byte) []byte {
+	data = append(data, Int64ToFixedBytes(int64(len(b)))...)
+	return append(data, b...)
+}
+
+// Append the outpoint of the input, i.e. the output it spends, and its sequence.
+func appendInput(data []byte, input *model.Input) ([]byte, error) {
+	prevHash, err := HexToBytes(input.PrevTxHash)
+	if err != nil {
+		return nil, err
+	}
+	data = appendWithLength(data, prevHash)
+	data = append(data, Int64ToFixedBytes(input.Index)...)
+	return append(data, Int64ToFixedBytes(input.Sequence)...), nil
+}
+
+func appendOutput(data []byte, output *model.Output) []byte {
+	data = append(data, AmountToBytes(output.Value)...)
+	return appendWithLength(data, GetLockingScript(output))
+}
+
+// Return the data the signature of input at index signs, given the output it spends and
+// the signature hash type. It always covers the version, lock time, height and
+// replaceable flag of the transaction, the outpoint and sequence of the input, and the
+// value and locking script of the spent output. Other inputs are covered unless SIGHASH_ANYONECANPAY, and outputs are
+// covered according to the lower bits of hashType. No signature is ever covered.
+// READONLY:
+// * tx
+// * spent
+func GetSignatureData(tx *model.Transaction, index int, spent *model.Output, hashType byte) ([]byte, error) {
+	if index < 0 || index >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input index %d is out of range", index)
+	}
+	if spent == nil {
+		return nil, errors.New("output spent by the input is missing")
+	}
+	if !IsValidSigHashType(hashType) {
+		return nil, fmt.Errorf("invalid signature hash type: %#x", hashType)
+	}
+	var data []byte
+	var err error
+	data = append(data, Int64ToFixedBytes(int64(tx.Version))...)
+
+	// Inputs.
+	if hashType&SIGHASH_ANYONECANPAY == 0 {
+		data = append(data, Int64ToFixedBytes(int64(len(tx.Inputs)))...)
+		for _, input := range tx.Inputs {
+			data, err = appendInput(data, input)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	data = append(data, Int64ToFixedBytes(int64(index))...)
+	data, err = appendInput(data, tx.Inputs[index])
+	if err != nil {
+		return nil, err
+	}
+	data = appendOutput(data, spent)
+
+	// Outputs.
+	switch hashType &^ SIGHASH_ANYONECANPAY {
+	case SIGHASH_ALL:
+		data = append(data, Int64ToFixedBytes(int64(len(tx.Outputs)))...)
+		for _, output := range tx.Outputs {
+			data = appendOutput(data, output)
+		}
+	case SIGHASH_SINGLE:
+		if index >= len(tx.Outputs) {
+			return nil, fmt.Errorf("input %d has no output at the same index to sign with SIGHASH_SINGLE", index)
+		}
+		data = appendOutput(data, tx.Outputs[index])
+	}
+
+	data = append(data, Int64ToFixedBytes(tx.LockTime)...)
+	data = append(data, Int64ToFixedBytes(tx.Height)...)
+	data = append(data, BoolToBytes(tx.Replaceable)...)
+	return append(data, hashType), nil
+}
+
+// Split a signature of an input into the signature itself and its hash type.
+func SplitSignature(sig []byte) ([]byte, byte, error) {
+	if len(sig) == 0 {
+		return nil, 0, errors.New("signature is empty")
+	}
+	hashType := sig[len(sig)-1]
+	if !IsValidSigHashType(hashType) {
+		return nil, 0, fmt.Errorf("invalid signature hash type: %#x", hashType)
+	}
+	return sig[:len(sig)-1], hashType, nil
+}
+
+// Return the signature of the input at index, which spends the spent output, with the
+// hash type appended. This is what the unlocking script pushes.
+// READONLY:
+// * tx
+// * spent
+func CreateSignature(sk PrivateKey, tx *model.Transaction, index int, spent *model.Output, hashType byte) ([]byte, error) {
+	data, err := GetSignatureData(tx, index, spent, hashType)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sk.Sign(data)
+	if err != nil {
+		return nil, err
+	}
+	return append(sig, hashType), nil
+}
+
+// Sign the input at index, which spends the spent output paying to the key or its hash,
+// with the hash type. The unlocking script pushes the signature, and the public key if the
+// output only has its hash. The transaction hash doesn't change because it doesn't cover
+// unlocking scripts.
+// MUTABLE:
+// * tx
+// READONLY:
+// * spent
+func SignInput(sk PrivateKey, tx *model.Transaction, index int, spent *model.Output, hashType byte) error {
+	sig, err := CreateSignature(sk, tx, index, spent, hashType)
+	if err != nil {
+		return err
+	}
+	pk := EncodePublicKey(sk.Public())
+	locking := GetLockingScript(spent)
+	unlocking := script.AppendData(nil, sig)
+	if bytes.Equal(locking, script.PayToPublicKeyHash(HashPublicKey(pk))) {
+		unlocking = script.AppendData(unlocking, pk)
+	} else if !bytes.Equal(locking, script.PayToPublicKey(pk)) {
+		return fmt.Errorf("input %d spends an output not paid to the key", index)
+	}
+	tx.Inputs[index].Signature = nil
+	tx.Inputs[index].Script = unlocking
+	return nil
+}
+
+// Verify that the unlocking script of the input at index unlocks the locking script of
+// the spent output.
+// READONLY:
+// * tx
+// * spent
+func VerifyInput(tx *model.Transaction, index int, spent *model.Output) error {
+	if index < 0 || index >= len(tx.Inputs) {
+		return fmt.Errorf("input index %d is out of range", index)
+	}
+	checker := txChecker{tx: tx, index: index, spent: spent}
+	return script.Verify(GetUnlockingScript(tx.Inputs[index]), GetLockingScript(spent), checker)
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// Create an unsigned transaction with 2 inputs and 2 outputs, and the outputs it spends.
+func createTestSighashTx(t *testing.T) (*model.Transaction, []*model.Output) {
+	sk := createTestKey(t)
+	spent := []*model.Output{NewOutput(100, sk.Public()), NewOutput(200, sk.Public())}
+	tx := &model.Transaction{
+		Version: TX_VERSION,
+		Inputs:  []*model.Input{{PrevTxHash: "aa", Index: 0}, {PrevTxHash: "bb", Index: 1}},
+		Outputs: []*model.Output{NewOutput(150, sk.Public()), NewOutput(140, sk.Public())},
+	}
+	for i := range tx.Inputs {
+		assert.Nil(t, SignInput(sk, tx, i, spent[i], SIGHASH_ALL))
+	}
+	return tx, spent
+}
+
+func TestSigHashAll(t *testing.T) {
+	tx, spent := createTestSighashTx(t)
+	assert.Nil(t, VerifyInput(tx, 0, spent[0]))
+
+	changes := []func(tx *model.Transaction){
+		func(tx *model.Transaction) { tx.Inputs[1].Index = 2 },
+		func(tx *model.Transaction) { tx.Outputs[1].Value = 1 },
+		func(tx *model.Transaction) { tx.Outputs = tx.Outputs[:1] },
+		func(tx *model.Transaction) { tx.Version = 2 },
+		func(tx *model.Transaction) { tx.LockTime = 10 },
+		func(tx *model.Transaction) { tx.Height = 10 },
+		func(tx *model.Transaction) { tx.Replaceable = true },
+	}
+	for _, change := range changes {
+		changed := proto.Clone(tx).(*model.Transaction)
+		change(changed)
+		assert.NotNil(t, VerifyInput(changed, 0, spent[0]))
+	}
+	// The value of the spent output is signed as well.
+	changed := proto.Clone(spent[0]).(*model.Output)
+	changed.Value = 101
+	assert.NotNil(t, VerifyInput(tx, 0, changed))
+}
+
+func TestSigHashTypes(t *testing.T) {
+	tx, spent := createTestSighashTx(t)
+	sk := createTestKey(t)
+	spent[0] = NewOutput(spent[0].Value, sk.Public())
+
+	// NONE allows any output.
+	assert.Nil(t, SignInput(sk, tx, 0, spent[0], SIGHASH_NONE))
+	changed := proto.Clone(tx).(*model.Transaction)
+	changed.Outputs[0].Value = 1
+	changed.Outputs = append(changed.Outputs, &model.Output{Value: 1})
+	assert.Nil(t, VerifyInput(changed, 0, spent[0]))
+	changed.Inputs[1].Index = 2
+	assert.NotNil(t, VerifyInput(changed, 0, spent[0]))
+
+	// SINGLE only signs the output at the same index.
+	assert.Nil(t, SignInput(sk, tx, 0, spent[0], SIGHASH_SINGLE))
+	changed = proto.Clone(tx).(*model.Transaction)
+	changed.Outputs[1].Value = 1
+	assert.Nil(t, VerifyInput(changed, 0, spent[0]))
+	changed.Outputs[0].Value = 1
+	assert.NotNil(t, VerifyInput(changed, 0, spent[0]))
+	tx.Outputs = tx.Outputs[:1]
+	assert.NotNil(t, SignInput(sk, tx, 1, spent[1], SIGHASH_SINGLE))
+
+	// ANYONECANPAY allows other inputs to be added.
+	assert.Nil(t, SignInput(sk, tx, 0, spent[0], SIGHASH_ALL|SIGHASH_ANYONECANPAY))
+	changed = proto.Clone(tx).(*model.Transaction)
+	changed.Inputs = append(changed.Inputs, &model.Input{PrevTxHash: "cc"})
+	assert.Nil(t, VerifyInput(changed, 0, spent[0]))
+	changed.Outputs[0].Value = 1
+	assert.NotNil(t, VerifyInput(changed, 0, spent[0]))
+
+	// Unknown hash types are rejected.
+	assert.NotNil(t, SignInput(sk, tx, 0, spent[0], 0x04))
+	sig, err := CreateSignature(sk, tx, 0, spent[0], SIGHASH_ALL)
+	assert.Nil(t, err)
+	sig[len(sig)-1] = 0x00
+	tx.Inputs[0].Script = script.AppendData(script.AppendData(nil, sig), EncodePublicKey(sk.Public()))
+	assert.NotNil(t, VerifyInput(tx, 0, spent[0]))
+}
+
+func TestTxHashExcludesSignatures(t *testing.T) {
+	tx, spent := createTestSighashTx(t)
+	assert.Nil(t, FillTxHash(tx))
+	hash := tx.Hash
+	witnessHash, err := GetWitnessHash(tx)
+	assert.Nil(t, err)
+
+	// Re-signing with another hash type changes the witness hash only.
+	sk := createTestKey(t)
+	spent[0] = NewOutput(spent[0].Value, sk.Public())
+	assert.Nil(t, SignInput(sk, tx, 0, spent[0], SIGHASH_NONE))
+	assert.Nil(t, FillTxHash(tx))
+	assert.Equal(t, hash, tx.Hash)
+	newWitnessHash, err := GetWitnessHash(tx)
+	assert.Nil(t, err)
+	assert.NotEqual(t, witnessHash, newWitnessHash)
+}
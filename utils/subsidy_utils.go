@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"github.com/Luismorlan/btc_in_go/config"
+	"github.com/Luismorlan/btc_in_go/model"
+)
+
+// Return the new coins a block at the given height can create, in base units. It starts at
+// COINBASE_REWARD and halves every HALVING_INTERVAL blocks, but never goes below
+// MIN_COINBASE_REWARD.
+func GetBlockSubsidy(height int64, c config.AppConfig) int64 {
+	subsidy := c.COINBASE_REWARD
+	if c.HALVING_INTERVAL > 0 {
+		halvings := height / c.HALVING_INTERVAL
+		// Shifting by 63 or more is zero anyway.
+		if halvings >= 63 {
+			subsidy = 0
+		} else {
+			subsidy >>= uint(halvings)
+		}
+	}
+	if subsidy < c.MIN_COINBASE_REWARD {
+		subsidy = c.MIN_COINBASE_REWARD
+	}
+	return subsidy
+}
+
+// Return the total subsidy of all blocks from height 1 to the given height, which is the
+// most coins that can exist at that height.
+func GetTotalSubsidy(height int64, c config.AppConfig) int64 {
+	var total int64
+	for h := int64(1); h <= height; {
+		// Blocks from h to end all have the same subsidy.
+		end := height
+		if c.HALVING_INTERVAL > 0 {
+			if eraEnd := (h/c.HALVING_INTERVAL+1)*c.HALVING_INTERVAL - 1; eraEnd < end {
+				end = eraEnd
+			}
+		}
+		subsidy := GetBlockSubsidy(h, c)
+		if subsidy == 0 {
+			break
+		}
+		total += subsidy * (end - h + 1)
+		h = end + 1
+	}
+	return total
+}
+
+// Return the total value of all UTXOs in the ledger, in base units. Fees are paid back to
+// miners, so at tail this is all coins issued so far.
+// READONLY:
+// * l
+func GetLedgerValue(l *model.Ledger) (int64, error) {
+	var total int64
+	var err error
+	for _, output := range l.L {
+		total, err = AddAmount(total, output.Value)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/config"
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBlockSubsidy(t *testing.T) {
+	c := config.AppConfig{COINBASE_REWARD: 100, HALVING_INTERVAL: 10}
+	assert.Equal(t, int64(100), GetBlockSubsidy(1, c))
+	assert.Equal(t, int64(100), GetBlockSubsidy(9, c))
+	assert.Equal(t, int64(50), GetBlockSubsidy(10, c))
+	assert.Equal(t, int64(25), GetBlockSubsidy(29, c))
+	assert.Equal(t, int64(0), GetBlockSubsidy(1000, c))
+
+	c.MIN_COINBASE_REWARD = 20
+	assert.Equal(t, int64(20), GetBlockSubsidy(30, c))
+	assert.Equal(t, int64(20), GetBlockSubsidy(1000, c))
+
+	// Never halves.
+	assert.Equal(t, int64(100), GetBlockSubsidy(1000, config.AppConfig{COINBASE_REWARD: 100}))
+}
+
+func TestGetTotalSubsidy(t *testing.T) {
+	c := config.AppConfig{COINBASE_REWARD: 100, HALVING_INTERVAL: 10}
+	assert.Equal(t, int64(0), GetTotalSubsidy(0, c))
+	assert.Equal(t, int64(900), GetTotalSubsidy(9, c))
+	assert.Equal(t, int64(900+50*5), GetTotalSubsidy(14, c))
+	// The supply is bounded.
+	assert.Equal(t, GetTotalSubsidy(1000, c), GetTotalSubsidy(100000, c))
+	for h := int64(0); h < 100; h++ {
+		assert.Equal(t, GetTotalSubsidy(h, c)+GetBlockSubsidy(h+1, c), GetTotalSubsidy(h+1, c))
+	}
+
+	c.MIN_COINBASE_REWARD = 20
+	assert.Equal(t, GetTotalSubsidy(1000, c)+20*1000, GetTotalSubsidy(2000, c))
+}
+
+func TestGetLedgerValue(t *testing.T) {
+	l := model.NewLedger()
+	pk := createTestKey(t).Public()
+	ProcessInputsAndOutputs(CreateCoinbaseTx(100, pk, 1), l, nil, ValidationContext{Height: 1})
+	ProcessInputsAndOutputs(CreateCoinbaseTx(50, pk, 2), l, nil, ValidationContext{Height: 2})
+	v, err := GetLedgerValue(l)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(150), v)
+}
@@ -1,11 +1,13 @@
 package utils
 
 import (
-	"crypto/rsa"
 	"errors"
 	"fmt"
 
+	"github.com/Luismorlan/btc_in_go/config"
 	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
+	"google.golang.org/protobuf/proto"
 )
 
 // GetInputBytes converts input to byte slice. With or without the signature.
@@ -17,25 +19,32 @@ func GetInputBytes(input *model.Input, withSig bool) ([]byte, error) {
 	}
 	data = append(data, prevHash...)
 	data = append(data, Int64ToBytes(input.Index)...)
+	data = append(data, Int64ToFixedBytes(input.Sequence)...)
+	if withSig {
+		data = appendWithLength(data, input.Signature)
+		data = appendWithLength(data, input.Script)
+	}
 	return data, nil
 }
 
 func GetOutputBytes(output *model.Output) []byte {
 	var data []byte
-	data = append(data, Float64ToBytes(output.Value)...)
-
+	data = append(data, AmountToBytes(output.Value)...)
+	data = append(data, Int64ToFixedBytes(int64(output.KeyType))...)
 	data = append(data, output.PublicKey...)
+	data = appendWithLength(data, output.Script)
 	return data
 }
 
-// Concat all inputs (including signature) and outputs raw data in byte slices.
-// withHash specifies whether TX hash should be included or not.
-func GetTransactionBytes(tx *model.Transaction, withHash bool) ([]byte, error) {
+// Concat all inputs and outputs raw data in byte slices. withSig specifies whether input
+// signatures should be included or not.
+func GetTransactionBytes(tx *model.Transaction, withSig bool) ([]byte, error) {
 	var data []byte
+	data = append(data, Int64ToFixedBytes(int64(tx.Version))...)
 
 	for i := 0; i < len(tx.Inputs); i++ {
 		input := tx.Inputs[i]
-		inputData, err := GetInputBytes(input, true /*withSig=*/)
+		inputData, err := GetInputBytes(input, withSig)
 		if err != nil {
 			return nil, err
 		}
@@ -50,62 +59,86 @@ func GetTransactionBytes(tx *model.Transaction, withHash bool) ([]byte, error) {
 
 	// This is needed for Coinbase transaction to avoid block with only CB tx has same txid.
 	data = append(data, Int64ToBytes(tx.Height)...)
-
-	if withHash {
-		hashBytes, err := HexToBytes(tx.Hash)
-		if err != nil {
-			return nil, err
-		}
-		data = append(data, hashBytes...)
-	}
+	data = append(data, BoolToBytes(tx.Replaceable)...)
+	data = append(data, Int64ToFixedBytes(tx.LockTime)...)
 
 	return data, nil
 }
 
-// GetInputDataToSign fetches the input from the transaction and return it in byte stream.
-func GetInputDataToSignByIndex(t *model.Transaction, index int) ([]byte, error) {
-	var data []byte
-
-	if len(t.Inputs)-1 < index {
-		return nil, errors.New("index is out of the range")
+// Return the transaction hash, which doesn't cover signatures so that re-signing can't
+// change it.
+func GetTxHash(tx *model.Transaction) (string, error) {
+	data, err := GetTransactionBytes(tx, false /*withSig=*/)
+	if err != nil {
+		return "", err
 	}
-	input := t.Inputs[index]
-	// Don't include signature since we haven't signed it yet.
-	inputData, err := GetInputBytes(input, false /*withSig=*/)
+	return BytesToHex(SHA256(data)), nil
+}
+
+// Return the witness hash of the transaction, which covers signatures too. Blocks commit
+// to it besides the transaction hash.
+func GetWitnessHash(tx *model.Transaction) (string, error) {
+	data, err := GetTransactionBytes(tx, true /*withSig=*/)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	data = append(data, inputData...)
+	return BytesToHex(SHA256(data)), nil
+}
 
-	for i := 0; i < len(t.Outputs); i++ {
-		output := t.Outputs[i]
-		outputData := GetOutputBytes(output)
-		data = append(data, outputData...)
+// Where a transaction is validated, besides the ledger it spends from.
+type ValidationContext struct {
+	// Height of the block including the transaction, or the next block for a pending one.
+	Height int64
+	// Median time past of the parent of that block, see GetMedianTimePast.
+	MedianTimePast int64
+	// Outputs of a coinbase transaction can only be spent in a block at least this many
+	// blocks above the block creating them.
+	CoinbaseMaturity int64
+}
+
+// Whether the UTXO in the ledger can be spent in the context, which is false for a
+// coinbase output that is not mature yet. Everything is mature if maturity is not positive.
+// READONLY:
+// * l
+func IsMature(l *model.Ledger, utxo model.UTXOLite, ctx ValidationContext) bool {
+	meta, exist := l.Meta[utxo]
+	if !exist || !meta.Coinbase || ctx.CoinbaseMaturity <= 0 {
+		return true
 	}
-	return data, nil
+	return ctx.Height-meta.Height >= ctx.CoinbaseMaturity
 }
 
 // A transaction is valid if:
 // 1. All inputs are UTXO.
 // 2. Total outputs are smaller or equal to inputs.
 // 3. Outputs are non-negative number.
-// 4. Signatures are valid.
+// 4. Unlocking scripts unlock the outputs spent.
 // 5. No 2 inputs claiming the same UTXO in this transaction.
 // 6. Hash matches.
+// 7. Coinbase outputs spent are mature.
+// 8. Lock time and relative locks of inputs have passed.
 // This function
-func IsValidTransaction(tx *model.Transaction, l *model.Ledger) error {
-	var totalInput = 0.0
-	var totalOutput = 0.0
+func IsValidTransaction(tx *model.Transaction, l *model.Ledger, ctx ValidationContext) error {
+	var totalInput int64 = 0
+	var totalOutput int64 = 0
 
 	// Tx hash should match.
-	txBytes, err := GetTransactionBytes(tx, false /*withHash*/)
+	hash, err := GetTxHash(tx)
 	if err != nil {
 		return err
 	}
-	if BytesToHex(SHA256(txBytes)) != tx.Hash {
+	if hash != tx.Hash {
 		return fmt.Errorf("transaction contains a invalid hash: %+v", tx.String())
 	}
 
+	// Transaction must not be locked in the block.
+	if err := CheckLockTime(tx, ctx); err != nil {
+		return err
+	}
+	if err := CheckSequenceLocks(tx, l, ctx); err != nil {
+		return err
+	}
+
 	// Store all seen UTXOs to avoid double spending.
 	seenUtxo := make(map[model.UTXOLite]bool)
 
@@ -117,19 +150,19 @@ func IsValidTransaction(tx *model.Transaction, l *model.Ledger) error {
 		if !ok {
 			return fmt.Errorf("transaction input has been spent: %+v", tx.String())
 		}
-		totalInput += output.Value
-
-		// Verify signature.
-		inputData, err := GetInputDataToSignByIndex(tx, i)
+		if !IsMature(l, model.GetUtxoLite(&inputUtxo), ctx) {
+			meta := l.Meta[model.GetUtxoLite(&inputUtxo)]
+			return fmt.Errorf("input %s:%d spends coinbase output of height %d before maturity at height %d",
+				input.PrevTxHash, input.Index, meta.Height, meta.Height+ctx.CoinbaseMaturity)
+		}
+		totalInput, err = AddAmount(totalInput, output.Value)
 		if err != nil {
 			return err
 		}
-		pk := BytesToPublicKey(output.PublicKey)
-		if pk == nil {
-			return errors.New("invalid bytes when reconstructing public key")
-		}
-		if isValid := Verify(inputData, pk, input.Signature); !isValid {
-			return errors.New("signature verification failed")
+
+		// Verify the unlocking script.
+		if err := VerifyInput(tx, i, output); err != nil {
+			return fmt.Errorf("input %d: %s", i, err)
 		}
 
 		// No double spending.
@@ -140,54 +173,94 @@ func IsValidTransaction(tx *model.Transaction, l *model.Ledger) error {
 	}
 
 	for i := 0; i < len(tx.Outputs); i++ {
-		// Output should be non-negative number.
+		// Output should be non-negative number within money range.
 		output := tx.Outputs[i]
-		if output.Value < 0 {
+		if !IsValidAmount(output.Value) {
 			return fmt.Errorf("invalid output: %+v", output)
 		}
-		totalOutput += output.Value
+		// Output must be spendable by a script of limited size.
+		if len(GetLockingScript(output)) == 0 || len(output.Script) > script.MAX_SCRIPT_SIZE {
+			return fmt.Errorf("output %d has no locking script or one larger than %d bytes", i, script.MAX_SCRIPT_SIZE)
+		}
+		if len(output.Script) > 0 && len(output.PublicKey) > 0 {
+			return fmt.Errorf("output %d has both a locking script and a public key", i)
+		}
+		totalOutput, err = AddAmount(totalOutput, output.Value)
+		if err != nil {
+			return err
+		}
 	}
 
 	if totalInput < totalOutput {
-		return fmt.Errorf("total input %+v is smaller than total output %+v", totalInput, totalOutput)
+		return fmt.Errorf("total input %s is smaller than total output %s", FormatAmount(totalInput), FormatAmount(totalOutput))
 	}
 	return nil
 }
 
 // Calculate total transaction fee given transaction and ledger. This function will not
-// modify ledger.
-func CalcTxFee(txs []*model.Transaction, l *model.Ledger) (float64, error) {
-	var fee float64
+// modify ledger. A transaction can spend outputs of transactions before it in txs.
+func CalcTxFee(txs []*model.Transaction, l *model.Ledger) (int64, error) {
+	fees, err := GetTxFees(txs, l)
+	if err != nil {
+		return 0, err
+	}
+	var fee int64
+	for _, f := range fees {
+		fee, err = AddAmount(fee, f)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return fee, nil
+}
+
+// Return the fee of each transaction given transactions and ledger. This function will
+// not modify ledger. A transaction can spend outputs of transactions before it in txs.
+func GetTxFees(txs []*model.Transaction, l *model.Ledger) ([]int64, error) {
+	fees := []int64{}
+	// Outputs created by transactions already processed.
+	created := make(map[model.UTXOLite]*model.Output)
 	for i := 0; i < len(txs); i++ {
 		tx := txs[i]
 
-		var totalInput = 0.0
-		var totalOutput = 0.0
+		var totalInput int64 = 0
+		var totalOutput int64 = 0
+		var err error
 
 		for j := 0; j < len(tx.Inputs); j++ {
 			// Verify the input is using UTXO.
 			input := tx.Inputs[j]
-			inputUtxo := CreateUtxoFromInput(input)
-			output, ok := l.L[model.GetUtxoLite(&inputUtxo)]
+			utxo := CreateUtxoFromInput(input)
+			inputUtxo := model.GetUtxoLite(&utxo)
+			output, ok := l.L[inputUtxo]
 			if !ok {
-				return 0.0, errors.New("unexpected error: doesn't find utxo in ledger")
+				output, ok = created[inputUtxo]
+			}
+			if !ok {
+				return nil, errors.New("unexpected error: doesn't find utxo in ledger")
+			}
+			totalInput, err = AddAmount(totalInput, output.Value)
+			if err != nil {
+				return nil, err
 			}
-			totalInput += output.Value
 		}
 
 		for j := 0; j < len(tx.Outputs); j++ {
 			output := tx.Outputs[j]
-			totalOutput += output.Value
+			totalOutput, err = AddAmount(totalOutput, output.Value)
+			if err != nil {
+				return nil, err
+			}
+			created[model.UTXOLite{PrevTxHash: tx.Hash, Index: int64(j)}] = output
 		}
 
 		if totalOutput > totalInput {
-			return 0.0, errors.New("total output is greater than total inputs")
+			return nil, errors.New("total output is greater than total inputs")
 		}
-
-		fee += totalOutput - totalInput
+		fees = append(fees, totalInput-totalOutput)
 	}
 
-	return fee, nil
+	return fees, nil
 }
 
 // Fill hash simply compute the SHA256 hash for the transaction raw data and set the hash.
@@ -195,37 +268,48 @@ func FillTxHash(tx *model.Transaction) error {
 	if tx == nil {
 		return errors.New("input transaction to hash cannot be nil")
 	}
-	data, err := GetTransactionBytes(tx, false /*withHash*/)
+	hash, err := GetTxHash(tx)
 	if err != nil {
 		return err
 	}
-	hash := SHA256(data)
-	tx.Hash = BytesToHex(hash)
+	tx.Hash = hash
 	return nil
 }
 
 // A valid coinbase transaction should contains 0 input and 1 output. And total reward should be
-// smaller than transaction fee + default reward.
+// smaller than transaction fee + block subsidy at the height. Its height must be the height of
+// the block, which decides when its output is mature.
 // READONLY:
 // * tx
-func IsValidCoinbase(tx *model.Transaction, maxFee float64) error {
+func IsValidCoinbase(tx *model.Transaction, fee int64, height int64, c config.AppConfig) error {
 	// Tx hash should match.
-	txBytes, err := GetTransactionBytes(tx, false /*withHash*/)
+	hash, err := GetTxHash(tx)
 	if err != nil {
 		return err
 	}
-	if BytesToHex(SHA256(txBytes)) != tx.Hash {
+	if hash != tx.Hash {
 		return fmt.Errorf("coinbase transaction contains a invalid hash: %+v", tx.String())
 	}
 
+	if tx.Height != height {
+		return fmt.Errorf("coinbase height %d doesn't match block height %d", tx.Height, height)
+	}
+	if tx.LockTime != 0 {
+		return fmt.Errorf("coinbase can't have lock time %d", tx.LockTime)
+	}
+
 	// Should contains 0 input and 1 output.
 	if len(tx.Inputs) != 0 || len(tx.Outputs) != 1 {
 		return fmt.Errorf("coinbase should contain 0 input and 1 output, actual: %d, %d", len(tx.Inputs), len(tx.Outputs))
 	}
 
 	// total fee should be smaller than maxFee.
-	if tx.Outputs[0].Value > maxFee {
-		return fmt.Errorf("total fee: %f is greater than allowed: %f", tx.Outputs[0].Value, maxFee)
+	maxFee, err := AddAmount(fee, GetBlockSubsidy(height, c))
+	if err != nil {
+		return err
+	}
+	if !IsValidAmount(tx.Outputs[0].Value) || tx.Outputs[0].Value > maxFee {
+		return fmt.Errorf("total fee: %s is greater than allowed: %s", FormatAmount(tx.Outputs[0].Value), FormatAmount(maxFee))
 	}
 
 	return nil
@@ -234,38 +318,46 @@ func IsValidCoinbase(tx *model.Transaction, maxFee float64) error {
 //Create a transaction with a single output, which is the miner's public key.
 // READONLY:
 // *pk
-func CreateCoinbaseTx(totalReward float64, pk []byte, height int64) *model.Transaction {
+func CreateCoinbaseTx(totalReward int64, pk PublicKey, height int64) *model.Transaction {
 	tx := &model.Transaction{
-		Outputs: []*model.Output{{
-			Value:     totalReward,
-			PublicKey: pk,
-		}},
-		Height: height,
+		Outputs: []*model.Output{NewOutput(totalReward, pk)},
+		Height:  height,
+		Version: TX_VERSION,
 	}
 	// Ignore error because tx can never be nil.
 	FillTxHash(tx)
 	return tx
 }
 
-// Return all transactions in the pool.
-// TODO: pre-allocate all memory by size.
-func GetAllTxsInPool(txPool *model.TransactionPool) []*model.Transaction {
-	txs := []*model.Transaction{}
-	for _, tx := range txPool.TxPool {
-		txs = append(txs, tx)
-	}
-	return txs
-}
-
 // Create a pending transaction to transfer money to users with public key
 // wallet : a pointer to a wallet struct
 // outputs : an array of struct Output
+// fee : transaction fee in base units, the change sent back is inputs - outputs - fee
 // READONLY:
 // * wallet
-func CreatePendingTransaction(sk *rsa.PrivateKey, utxos map[model.UTXOLite]*model.Output, outputs []*model.Output) (*model.Transaction, error) {
+func CreatePendingTransaction(sk PrivateKey, utxos map[model.UTXOLite]*model.Output, outputs []*model.Output, fee int64) (*model.Transaction, error) {
+	tx, err := CreateUnsignedTransaction(utxos, outputs, fee, NewOutput(0, sk.Public()))
+	if err != nil {
+		return &model.Transaction{}, err
+	}
+	err = SignTransaction(sk, tx, utxos)
+	if err != nil {
+		return &model.Transaction{}, err
+	}
+	return tx, nil
+}
+
+// Create a transaction spending utxos to outputs, paying fee base units. The change
+// sent back is a copy of change with inputs - outputs - fee as its value, or nothing if
+// nothing is left. The transaction is not signed, and its hash is filled.
+// READONLY:
+// * utxos
+// * change
+func CreateUnsignedTransaction(utxos map[model.UTXOLite]*model.Output, outputs []*model.Output, fee int64, change *model.Output) (*model.Transaction, error) {
 	var inputs []*model.Input
 	// Total money from all UTXOs
-	var totalInputValue float64 = 0
+	var totalInputValue int64 = 0
+	var err error
 	// building inputs for pending transaction
 	for utxo, output := range utxos {
 		input := &model.Input{
@@ -274,43 +366,65 @@ func CreatePendingTransaction(sk *rsa.PrivateKey, utxos map[model.UTXOLite]*mode
 		}
 
 		inputs = append(inputs, input)
-		totalInputValue += output.Value
+		totalInputValue, err = AddAmount(totalInputValue, output.Value)
+		if err != nil {
+			return &model.Transaction{}, err
+		}
 	}
 	// Total amount of money will be transferred to others
-	var totalOutputValue = 0.0
+	var totalOutputValue int64 = 0
 	for i := 0; i < len(outputs); i++ {
-		totalOutputValue += outputs[i].Value
+		totalOutputValue, err = AddAmount(totalOutputValue, outputs[i].Value)
+		if err != nil {
+			return &model.Transaction{}, err
+		}
+	}
+	if !IsValidAmount(fee) {
+		return &model.Transaction{}, fmt.Errorf("invalid fee: %s", FormatAmount(fee))
+	}
+	totalOutputValue, err = AddAmount(totalOutputValue, fee)
+	if err != nil {
+		return &model.Transaction{}, err
+	}
+	if totalOutputValue > totalInputValue {
+		return &model.Transaction{}, fmt.Errorf("insufficient balance: %s, need %s including fee %s", FormatAmount(totalInputValue), FormatAmount(totalOutputValue), FormatAmount(fee))
 	}
 
-	// Output with amount of money left after transfer, and transfer to self.
-	selfOutput := model.Output{
-		Value:     (totalInputValue - totalOutputValue),
-		PublicKey: PublicKeyToBytes(&sk.PublicKey),
+	// Output with amount of money left after transfer, and transfer to self. There is no
+	// change output if nothing is left.
+	if totalInputValue > totalOutputValue {
+		changeOutput := proto.Clone(change).(*model.Output)
+		changeOutput.Value = totalInputValue - totalOutputValue
+		outputs = append(outputs, changeOutput)
 	}
-	outputs = append(outputs, &selfOutput)
 
 	// build pending transaction with inputs and outputs
 	pendingTransaction := model.Transaction{
 		Inputs:  inputs,
 		Outputs: outputs,
+		Version: TX_VERSION,
 	}
+	err = FillTxHash(&pendingTransaction)
+	if err != nil {
+		return &model.Transaction{}, err
+	}
+	return &pendingTransaction, nil
+}
 
-	// sign inputs with own private key
-	for i := 0; i < len(inputs); i++ {
-		data, err := GetInputDataToSignByIndex(&pendingTransaction, i)
-		if err != nil {
-			return &model.Transaction{}, err
-		}
-		inputs[i].Signature, err = Sign(data, sk)
+// Sign all inputs of the transaction with the private key and SIGHASH_ALL, and fill the
+// transaction hash. spent has the outputs spent by the inputs. Any previous signature is
+// overwritten.
+// MUTABLE:
+// * tx
+// READONLY:
+// * spent
+func SignTransaction(sk PrivateKey, tx *model.Transaction, spent map[model.UTXOLite]*model.Output) error {
+	for i := 0; i < len(tx.Inputs); i++ {
+		input := tx.Inputs[i]
+		err := SignInput(sk, tx, i, spent[model.UTXOLite{PrevTxHash: input.PrevTxHash, Index: input.Index}], SIGHASH_ALL)
 		if err != nil {
-			return &model.Transaction{}, err
+			return err
 		}
 	}
-	transactionBytes, err := GetTransactionBytes(&pendingTransaction, false)
-	if err != nil {
-		return &model.Transaction{}, nil
-	}
-	// get Hash for transaction
-	pendingTransaction.Hash = BytesToHex(SHA256(transactionBytes))
-	return &pendingTransaction, nil
+	return FillTxHash(tx)
 }
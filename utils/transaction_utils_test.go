@@ -3,11 +3,72 @@ package utils
 import (
 	"testing"
 
+	"github.com/Luismorlan/btc_in_go/config"
+	"github.com/Luismorlan/btc_in_go/model"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateCoinbase(t *testing.T) {
-	_, pk := GenerateKeyPair(2048)
-	cb := CreateCoinbaseTx(1.0, PublicKeyToBytes(pk), 1)
-	assert.Nil(t, IsValidCoinbase(cb, 1.0))
+	sk := createTestKey(t)
+	cb := CreateCoinbaseTx(1.0, sk.Public(), 1)
+	assert.Nil(t, IsValidCoinbase(cb, 1, 1, config.AppConfig{}))
+	assert.NotNil(t, IsValidCoinbase(cb, 1, 2, config.AppConfig{}))
+	// The reward can't be more than fee plus subsidy.
+	assert.NotNil(t, IsValidCoinbase(cb, 0, 1, config.AppConfig{}))
+	assert.Nil(t, IsValidCoinbase(cb, 0, 1, config.AppConfig{COINBASE_REWARD: 1}))
+}
+
+func TestCoinbaseMaturity(t *testing.T) {
+	sk := createTestKey(t)
+	cb := CreateCoinbaseTx(100000, sk.Public(), 5)
+	l := model.NewLedger()
+	undo := &model.BlockUndo{}
+	ProcessInputsAndOutputs(cb, l, undo, ValidationContext{Height: 5})
+	utxo := model.UTXOLite{PrevTxHash: cb.Hash, Index: 0}
+	assert.Equal(t, model.UTXOMeta{Height: 5, Coinbase: true}, l.Meta[utxo])
+
+	tx, err := CreatePendingTransaction(sk, map[model.UTXOLite]*model.Output{utxo: cb.Outputs[0]},
+		[]*model.Output{NewOutput(50000, sk.Public())}, 0)
+	assert.Nil(t, err)
+	assert.NotNil(t, IsValidTransaction(tx, l, ValidationContext{Height: 14, CoinbaseMaturity: 10}))
+	assert.Nil(t, IsValidTransaction(tx, l, ValidationContext{Height: 15, CoinbaseMaturity: 10}))
+
+	// Spending the coinbase output forgets its metadata, and rolling back restores it.
+	spend := &model.BlockUndo{}
+	assert.Nil(t, HandleTransaction(tx, l, spend, ValidationContext{Height: 15, MedianTimePast: 1000, CoinbaseMaturity: 10}))
+	_, exist := l.Meta[utxo]
+	assert.False(t, exist)
+	assert.Equal(t, model.UTXOMeta{Height: 15, Time: 1000}, l.Meta[model.UTXOLite{PrevTxHash: tx.Hash, Index: 0}])
+	RollbackBlock(l, spend)
+	assert.Equal(t, model.UTXOMeta{Height: 5, Coinbase: true}, l.Meta[utxo])
+	RollbackBlock(l, undo)
+	assert.Equal(t, 0, len(l.Meta))
+	RollforwardBlock(l, undo)
+	assert.Equal(t, model.UTXOMeta{Height: 5, Coinbase: true}, l.Meta[utxo])
+}
+
+func TestCreatePendingTransaction(t *testing.T) {
+	sk := createTestKey(t)
+	cb := CreateCoinbaseTx(100000, sk.Public(), 1)
+	utxos := map[model.UTXOLite]*model.Output{{PrevTxHash: cb.Hash, Index: 0}: cb.Outputs[0]}
+	outputs := []*model.Output{NewOutput(50000, sk.Public())}
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(cb, l, nil, ValidationContext{})
+
+	tx, err := CreatePendingTransaction(sk, utxos, outputs, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(49000), tx.Outputs[1].Value)
+	fee, err := CalcTxFee([]*model.Transaction{tx}, l)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), fee)
+	assert.Nil(t, IsValidTransaction(tx, l, ValidationContext{}))
+
+	// Nothing is left for change.
+	tx, err = CreatePendingTransaction(sk, utxos, outputs, 50000)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tx.Outputs))
+
+	// Not enough left for the fee.
+	_, err = CreatePendingTransaction(sk, utxos, outputs, 50001)
+	assert.NotNil(t, err)
 }
@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// Fee rates are in base units per this many bytes.
+const FEE_RATE_BYTES = 1000
+
+// When transactions are evicted from a full pool, the pool minimum fee rate is raised to
+// the evicted fee rate plus this, so that a replacement must pay strictly more.
+const INCREMENTAL_RELAY_FEE_RATE = 1000
+
+// The pool minimum fee rate halves every this many seconds once raised.
+const POOL_MIN_FEE_RATE_HALF_LIFE = 12 * 60 * 60
+
+// Maximum number of pool transactions a replacement can evict, including descendants of
+// the conflicting transactions.
+const MAX_REPLACED_TXS = 100
+
+// Return the fee rate in base units per FEE_RATE_BYTES bytes.
+func GetFeeRate(fee int64, size int64) int64 {
+	if size <= 0 {
+		return 0
+	}
+	return fee * FEE_RATE_BYTES / size
+}
+
+// Return the fee in base units needed to pay feeRate for size bytes, rounded up.
+func GetFeeForSize(feeRate int64, size int64) int64 {
+	return (feeRate*size + FEE_RATE_BYTES - 1) / FEE_RATE_BYTES
+}
+
+// Add the transaction to pool at time now. The transaction can spend outputs in the UTXO
+// set at tail, as well as outputs created by other pool transactions, in which case they
+// become its parents. It must pay at least minFeeRate per FEE_RATE_BYTES bytes.
+// If it spends an output already spent by other pool transactions, it replaces them
+// only if all of them opted in to replace-by-fee, see checkReplacement. Return the
+// transactions replaced. The transaction is validated in ctx, which is the next block.
+// MUTABLE:
+// * pool
+// READONLY:
+// * l
+func AddTransactionToPool(pool *model.TransactionPool, tx *model.Transaction, l *model.Ledger, ctx ValidationContext, now int64, minFeeRate int64) ([]*model.Transaction, error) {
+	if _, exist := pool.TxPool[tx.Hash]; exist {
+		return nil, fmt.Errorf("existing transaction, will not process: %s", tx.Hash)
+	}
+
+	// Validate the transaction on a ledger containing only its inputs, either from the
+	// UTXO set or from the pool overlay.
+	view := model.NewLedger()
+	parents := make(map[string]bool)
+	conflicts := make(map[string]bool)
+	for _, input := range tx.Inputs {
+		utxo := CreateUtxoFromInput(input)
+		utxoLite := model.GetUtxoLite(&utxo)
+		if spender, exist := pool.Spends[utxoLite]; exist {
+			conflicts[spender] = true
+		}
+		if output, exist := pool.Outputs[utxoLite]; exist {
+			view.L[utxoLite] = output
+			parents[utxoLite.PrevTxHash] = true
+		} else if output, exist := l.L[utxoLite]; exist {
+			view.L[utxoLite] = output
+			if meta, exist := l.Meta[utxoLite]; exist {
+				view.Meta[utxoLite] = meta
+			}
+		}
+	}
+	err := IsValidTransaction(tx, view, ctx)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := CalcTxFee([]*model.Transaction{tx}, view)
+	if err != nil {
+		return nil, err
+	}
+	size := int64(proto.Size(tx))
+	if fee*FEE_RATE_BYTES < minFeeRate*size {
+		return nil, fmt.Errorf("transaction fee rate %d is below minimum %d", GetFeeRate(fee, size), minFeeRate)
+	}
+
+	replaced := []*model.Transaction{}
+	if len(conflicts) > 0 {
+		err = checkReplacement(pool, conflicts, parents, fee, size)
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range sortedHashes(conflicts) {
+			replaced = append(replaced, RemoveTransactionFromPool(pool, hash)...)
+		}
+	}
+
+	entry := &model.TxPoolEntry{
+		Tx:       tx,
+		Fee:      fee,
+		Size:     size,
+		Time:     now,
+		Parents:  parents,
+		Children: make(map[string]bool),
+	}
+	for parent := range parents {
+		pool.TxPool[parent].Children[tx.Hash] = true
+	}
+	for _, input := range tx.Inputs {
+		utxo := CreateUtxoFromInput(input)
+		pool.Spends[model.GetUtxoLite(&utxo)] = tx.Hash
+	}
+	for i, output := range tx.Outputs {
+		pool.Outputs[model.UTXOLite{PrevTxHash: tx.Hash, Index: int64(i)}] = output
+	}
+	pool.TxPool[tx.Hash] = entry
+	pool.Size += size
+	return replaced, nil
+}
+
+// Check whether a new transaction with the given fee, size and pool parents can replace
+// the conflicting pool transactions. Replacing is allowed only if:
+// 1. All conflicting transactions opted in to replace-by-fee.
+// 2. The new transaction doesn't depend on any transaction it replaces.
+// 3. At most MAX_REPLACED_TXS transactions are replaced, including descendants.
+// 4. The new fee rate is higher than the fee rate of each conflicting transaction.
+// 5. The new fee pays for all replaced transactions plus its own relay.
+// The last rule makes sure replacing can't be used to flood the network for free.
+// READONLY:
+// * pool
+func checkReplacement(pool *model.TransactionPool, conflicts map[string]bool, parents map[string]bool, fee int64, size int64) error {
+	replaced := make(map[string]bool)
+	for hash := range conflicts {
+		entry := pool.TxPool[hash]
+		if !entry.Tx.Replaceable {
+			return fmt.Errorf("conflicts with pool transaction %s which is not replaceable", hash)
+		}
+		if !isHigherFeeRate(fee, size, entry.Fee, entry.Size) {
+			return fmt.Errorf("fee rate %d must be higher than fee rate %d of replaced transaction %s",
+				GetFeeRate(fee, size), GetFeeRate(entry.Fee, entry.Size), hash)
+		}
+		getDescendantPackage(pool, hash, replaced)
+	}
+	if len(replaced) > MAX_REPLACED_TXS {
+		return fmt.Errorf("replaces %d transactions, more than %d", len(replaced), MAX_REPLACED_TXS)
+	}
+
+	var replacedFee int64
+	for hash := range replaced {
+		if parents[hash] {
+			return fmt.Errorf("spends output of transaction %s which it replaces", hash)
+		}
+		replacedFee += pool.TxPool[hash].Fee
+	}
+	if required := replacedFee + INCREMENTAL_RELAY_FEE_RATE*size/FEE_RATE_BYTES; fee < required {
+		return fmt.Errorf("fee %s must be at least %s to replace %d transactions",
+			FormatAmount(fee), FormatAmount(required), len(replaced))
+	}
+	return nil
+}
+
+// Remove the transaction and all its descendants from pool, since descendants spend
+// outputs that no longer exist. Return all removed transactions.
+// MUTABLE:
+// * pool
+func RemoveTransactionFromPool(pool *model.TransactionPool, hash string) []*model.Transaction {
+	entry, exist := pool.TxPool[hash]
+	if !exist {
+		return []*model.Transaction{}
+	}
+	removed := []*model.Transaction{}
+	for _, child := range sortedHashes(entry.Children) {
+		removed = append(removed, RemoveTransactionFromPool(pool, child)...)
+	}
+
+	tx := entry.Tx
+	for parent := range entry.Parents {
+		if p, exist := pool.TxPool[parent]; exist {
+			delete(p.Children, hash)
+		}
+	}
+	for _, input := range tx.Inputs {
+		utxo := CreateUtxoFromInput(input)
+		delete(pool.Spends, model.GetUtxoLite(&utxo))
+	}
+	for i := range tx.Outputs {
+		delete(pool.Outputs, model.UTXOLite{PrevTxHash: hash, Index: int64(i)})
+	}
+	delete(pool.TxPool, hash)
+	pool.Size -= entry.Size
+	return append(removed, tx)
+}
+
+// Return all transactions in the pool in topological order: a transaction always comes
+// after all its parents, so that handling them in order never spends a missing output.
+// Transactions without dependency between them are ordered by hash.
+func GetAllTxsInPool(pool *model.TransactionPool) []*model.Transaction {
+	txs := []*model.Transaction{}
+	visited := make(map[string]bool)
+	var visit func(hash string)
+	visit = func(hash string) {
+		entry, exist := pool.TxPool[hash]
+		if visited[hash] || !exist {
+			return
+		}
+		visited[hash] = true
+		for _, parent := range sortedHashes(entry.Parents) {
+			visit(parent)
+		}
+		txs = append(txs, entry.Tx)
+	}
+	hashes := []string{}
+	for hash := range pool.TxPool {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	for _, hash := range hashes {
+		visit(hash)
+	}
+	return txs
+}
+
+// Return hashes in the set in ascending order.
+func sortedHashes(set map[string]bool) []string {
+	hashes := []string{}
+	for hash := range set {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// Collect the transaction and all its descendants into pkg.
+func getDescendantPackage(pool *model.TransactionPool, hash string, pkg map[string]bool) {
+	if pkg[hash] {
+		return
+	}
+	pkg[hash] = true
+	for child := range pool.TxPool[hash].Children {
+		getDescendantPackage(pool, child, pkg)
+	}
+}
+
+// Evict transactions until the pool is at most maxBytes, or do nothing if maxBytes is not
+// positive. Since a transaction can't stay without its parents, a transaction is scored by
+// the fee rate of itself together with its descendants, and the lowest one is evicted with
+// its descendants first. The pool minimum fee rate is raised above the evicted fee rate.
+// Return all evicted transactions.
+// MUTABLE:
+// * pool
+func TrimPool(pool *model.TransactionPool, maxBytes int64, now int64) []*model.Transaction {
+	evicted := []*model.Transaction{}
+	for maxBytes > 0 && pool.Size > maxBytes {
+		worst := ""
+		var worstFee, worstSize int64
+		for hash := range pool.TxPool {
+			pkg := make(map[string]bool)
+			getDescendantPackage(pool, hash, pkg)
+			var fee, size int64
+			for h := range pkg {
+				fee += pool.TxPool[h].Fee
+				size += pool.TxPool[h].Size
+			}
+			if worst == "" || isHigherFeeRate(worstFee, worstSize, fee, size) ||
+				(!isHigherFeeRate(fee, size, worstFee, worstSize) && hash > worst) {
+				worst, worstFee, worstSize = hash, fee, size
+			}
+		}
+		evicted = append(evicted, RemoveTransactionFromPool(pool, worst)...)
+		if rate := GetFeeRate(worstFee, worstSize) + INCREMENTAL_RELAY_FEE_RATE; rate > pool.MinFeeRate {
+			pool.MinFeeRate = rate
+		}
+		pool.MinFeeRateTime = now
+	}
+	return evicted
+}
+
+// Halve the pool minimum fee rate for every POOL_MIN_FEE_RATE_HALF_LIFE passed since it
+// was last raised or decayed, so that it goes back to normal once the pool is not full.
+// MUTABLE:
+// * pool
+func DecayPoolMinFeeRate(pool *model.TransactionPool, now int64) {
+	for pool.MinFeeRate > 0 && now-pool.MinFeeRateTime >= POOL_MIN_FEE_RATE_HALF_LIFE {
+		pool.MinFeeRate /= 2
+		pool.MinFeeRateTime += POOL_MIN_FEE_RATE_HALF_LIFE
+	}
+	// Below the increment it's not meaningful anymore.
+	if pool.MinFeeRate < INCREMENTAL_RELAY_FEE_RATE/2 {
+		pool.MinFeeRate = 0
+	}
+}
+
+// Remove transactions that entered the pool before the given unix time, together with their
+// descendants. Return all removed transactions.
+// MUTABLE:
+// * pool
+func ExpirePool(pool *model.TransactionPool, before int64) []*model.Transaction {
+	expired := []*model.Transaction{}
+	for _, tx := range GetAllTxsInPool(pool) {
+		entry, exist := pool.TxPool[tx.Hash]
+		if exist && entry.Time < before {
+			expired = append(expired, RemoveTransactionFromPool(pool, tx.Hash)...)
+		}
+	}
+	return expired
+}
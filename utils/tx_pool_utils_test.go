@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// Create a signed transaction spending the output at utxo, sending value to self.
+func createTestSpendTx(t *testing.T, sk PrivateKey, utxo model.UTXOLite, output *model.Output, value int64) *model.Transaction {
+	tx, err := CreatePendingTransaction(sk, map[model.UTXOLite]*model.Output{utxo: output},
+		[]*model.Output{NewOutput(value, sk.Public())}, 0 /*fee=*/)
+	assert.Nil(t, err)
+	return tx
+}
+
+func TestTransactionPoolChain(t *testing.T) {
+	sk := createTestKey(t)
+	cb := CreateCoinbaseTx(100, sk.Public(), 1)
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(cb, l, nil, ValidationContext{})
+	pool := model.NewTransactionPool()
+	var err error
+
+	// tx2 spends an output of pending tx1, and tx3 spends an output of pending tx2.
+	tx1 := createTestSpendTx(t, sk, model.UTXOLite{PrevTxHash: cb.Hash, Index: 0}, cb.Outputs[0], 50)
+	tx2 := createTestSpendTx(t, sk, model.UTXOLite{PrevTxHash: tx1.Hash, Index: 0}, tx1.Outputs[0], 20)
+	tx3 := createTestSpendTx(t, sk, model.UTXOLite{PrevTxHash: tx2.Hash, Index: 0}, tx2.Outputs[0], 10)
+	_, err = AddTransactionToPool(pool, tx2, l, ValidationContext{}, 0, 0)
+	assert.NotNil(t, err)
+	_, err = AddTransactionToPool(pool, tx1, l, ValidationContext{}, 0, 0)
+	assert.Nil(t, err)
+	_, err = AddTransactionToPool(pool, tx2, l, ValidationContext{}, 0, 0)
+	assert.Nil(t, err)
+	_, err = AddTransactionToPool(pool, tx3, l, ValidationContext{}, 0, 0)
+	assert.Nil(t, err)
+	_, err = AddTransactionToPool(pool, tx3, l, ValidationContext{}, 0, 0)
+	assert.NotNil(t, err)
+	assert.Equal(t, map[string]bool{tx1.Hash: true}, pool.TxPool[tx2.Hash].Parents)
+	assert.Equal(t, map[string]bool{tx3.Hash: true}, pool.TxPool[tx2.Hash].Children)
+
+	// An output can only be spent once in the pool.
+	conflict := createTestSpendTx(t, sk, model.UTXOLite{PrevTxHash: tx1.Hash, Index: 0}, tx1.Outputs[0], 30)
+	_, err = AddTransactionToPool(pool, conflict, l, ValidationContext{}, 0, 0)
+	assert.NotNil(t, err)
+
+	// Parents always come first, and handling them in order on the ledger succeeds.
+	txs := GetAllTxsInPool(pool)
+	assert.Equal(t, []*model.Transaction{tx1, tx2, tx3}, txs)
+	errTxs, err := HandleTransactions(txs, GetLedgerDeepCopy(l), nil, ValidationContext{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(errTxs))
+
+	// Removing a transaction removes its descendants too.
+	removed := RemoveTransactionFromPool(pool, tx2.Hash)
+	assert.Equal(t, []*model.Transaction{tx3, tx2}, removed)
+	assert.Equal(t, 0, len(pool.TxPool[tx1.Hash].Children))
+	_, err = AddTransactionToPool(pool, conflict, l, ValidationContext{}, 0, 0)
+	assert.Nil(t, err)
+	RemoveTransactionFromPool(pool, tx1.Hash)
+	assert.Equal(t, 0, len(pool.TxPool))
+	assert.Equal(t, 0, len(pool.Outputs))
+	assert.Equal(t, 0, len(pool.Spends))
+}
+
+func TestAddTransactionToPoolMinFeeRate(t *testing.T) {
+	sk := createTestKey(t)
+	cb := CreateCoinbaseTx(100, sk.Public(), 1)
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(cb, l, nil, ValidationContext{})
+	pool := model.NewTransactionPool()
+	var err error
+
+	// The transaction pays no fee.
+	tx := createTestSpendTx(t, sk, model.UTXOLite{PrevTxHash: cb.Hash, Index: 0}, cb.Outputs[0], 50)
+	_, err = AddTransactionToPool(pool, tx, l, ValidationContext{}, 0, 1)
+	assert.NotNil(t, err)
+	_, err = AddTransactionToPool(pool, tx, l, ValidationContext{}, 7, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), pool.TxPool[tx.Hash].Time)
+	assert.Equal(t, pool.TxPool[tx.Hash].Size, pool.Size)
+}
+
+func TestTrimPool(t *testing.T) {
+	pool := model.NewTransactionPool()
+	// a has the lowest fee rate, but its child b pays for it.
+	addTestPoolEntry(pool, "aa", 10)
+	addTestPoolEntry(pool, "bb", 1000, "aa")
+	addTestPoolEntry(pool, "cc", 600)
+	addTestPoolEntry(pool, "dd", 200)
+
+	assert.Equal(t, 0, len(TrimPool(pool, 0, 0)))
+	evicted := TrimPool(pool, 300, 5)
+	assert.Equal(t, 1, len(evicted))
+	assert.Equal(t, "dd", evicted[0].Hash)
+	assert.Equal(t, int64(300), pool.Size)
+	assert.Equal(t, GetFeeRate(200, 100)+INCREMENTAL_RELAY_FEE_RATE, pool.MinFeeRate)
+	assert.Equal(t, int64(5), pool.MinFeeRateTime)
+
+	// The package of a and b is now the lowest, and goes together.
+	evicted = TrimPool(pool, 150, 5)
+	assert.Equal(t, 2, len(evicted))
+	assert.Contains(t, pool.TxPool, "cc")
+	assert.Equal(t, int64(100), pool.Size)
+}
+
+func TestDecayPoolMinFeeRate(t *testing.T) {
+	pool := model.NewTransactionPool()
+	pool.MinFeeRate = 8000
+	DecayPoolMinFeeRate(pool, POOL_MIN_FEE_RATE_HALF_LIFE-1)
+	assert.Equal(t, int64(8000), pool.MinFeeRate)
+	DecayPoolMinFeeRate(pool, 2*POOL_MIN_FEE_RATE_HALF_LIFE)
+	assert.Equal(t, int64(2000), pool.MinFeeRate)
+	DecayPoolMinFeeRate(pool, 10*POOL_MIN_FEE_RATE_HALF_LIFE)
+	assert.Equal(t, int64(0), pool.MinFeeRate)
+}
+
+func TestExpirePool(t *testing.T) {
+	pool := model.NewTransactionPool()
+	addTestPoolEntry(pool, "aa", 10)
+	addTestPoolEntry(pool, "bb", 10, "aa")
+	addTestPoolEntry(pool, "cc", 10)
+	pool.TxPool["aa"].Time = 1
+	pool.TxPool["bb"].Time = 5
+	pool.TxPool["cc"].Time = 5
+
+	// Descendants of an expired transaction go with it.
+	assert.Equal(t, 2, len(ExpirePool(pool, 2)))
+	assert.Equal(t, 1, len(pool.TxPool))
+	assert.Equal(t, 0, len(ExpirePool(pool, 5)))
+	assert.Equal(t, 1, len(ExpirePool(pool, 6)))
+}
+
+// Create a signed transaction opted in to replace-by-fee, spending the output at utxo and
+// paying the given fee.
+func createTestReplaceableTx(t *testing.T, sk PrivateKey, utxo model.UTXOLite, output *model.Output, value int64, fee int64) *model.Transaction {
+	tx, err := CreatePendingTransaction(sk, map[model.UTXOLite]*model.Output{utxo: output},
+		[]*model.Output{NewOutput(value, sk.Public())}, fee)
+	assert.Nil(t, err)
+	tx.Replaceable = true
+	assert.Nil(t, SignTransaction(sk, tx, map[model.UTXOLite]*model.Output{utxo: output}))
+	return tx
+}
+
+func TestReplaceByFee(t *testing.T) {
+	sk := createTestKey(t)
+	cb := CreateCoinbaseTx(100000, sk.Public(), 1)
+	l := model.NewLedger()
+	ProcessInputsAndOutputs(cb, l, nil, ValidationContext{})
+	pool := model.NewTransactionPool()
+	cbUtxo := model.UTXOLite{PrevTxHash: cb.Hash, Index: 0}
+
+	// orig is replaceable and has a child.
+	orig := createTestReplaceableTx(t, sk, cbUtxo, cb.Outputs[0], 50000, 1000)
+	child := createTestSpendTx(t, sk, model.UTXOLite{PrevTxHash: orig.Hash, Index: 0}, orig.Outputs[0], 10000)
+	_, err := AddTransactionToPool(pool, orig, l, ValidationContext{}, 0, 0)
+	assert.Nil(t, err)
+	_, err = AddTransactionToPool(pool, child, l, ValidationContext{}, 0, 0)
+	assert.Nil(t, err)
+
+	// The fee rate must be higher.
+	low := createTestReplaceableTx(t, sk, cbUtxo, cb.Outputs[0], 40000, 1000)
+	_, err = AddTransactionToPool(pool, low, l, ValidationContext{}, 0, 0)
+	assert.NotNil(t, err)
+
+	// The fee must pay for the replaced transactions and the relay of the replacement.
+	small := createTestReplaceableTx(t, sk, cbUtxo, cb.Outputs[0], 40000, 1001)
+	_, err = AddTransactionToPool(pool, small, l, ValidationContext{}, 0, 0)
+	assert.NotNil(t, err)
+
+	// The replacement can't spend outputs of the transactions it replaces.
+	spendsOrig := createTestSpendTx(t, sk, cbUtxo, cb.Outputs[0], 1000)
+	spendsOrig.Inputs = append(spendsOrig.Inputs, &model.Input{PrevTxHash: orig.Hash, Index: 1})
+	spendsOrig.Outputs[1].Value -= 10000
+	assert.Nil(t, SignTransaction(sk, spendsOrig, map[model.UTXOLite]*model.Output{
+		cbUtxo: cb.Outputs[0], {PrevTxHash: orig.Hash, Index: 1}: orig.Outputs[1]}))
+	_, err = AddTransactionToPool(pool, spendsOrig, l, ValidationContext{}, 0, 0)
+	assert.NotNil(t, err)
+
+	// A high enough fee replaces orig together with its child.
+	high := createTestReplaceableTx(t, sk, cbUtxo, cb.Outputs[0], 40000, 5000)
+	replaced, err := AddTransactionToPool(pool, high, l, ValidationContext{}, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.Transaction{child, orig}, replaced)
+	assert.Equal(t, 1, len(pool.TxPool))
+	assert.Equal(t, high.Hash, pool.Spends[cbUtxo])
+	assert.Equal(t, pool.TxPool[high.Hash].Size, pool.Size)
+
+	// A transaction paying no fee can't replace high.
+	_, err = AddTransactionToPool(pool, createTestSpendTx(t, sk, cbUtxo, cb.Outputs[0], 40000), l, ValidationContext{}, 0, 0)
+	assert.NotNil(t, err)
+}
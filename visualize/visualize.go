@@ -21,7 +21,7 @@ type input struct {
 }
 
 type output struct {
-	value     float64
+	value     string
 	publicKey string
 }
 
@@ -176,7 +176,7 @@ func txToTx(tx *model.Transaction) transaction {
 
 	for i := 0; i < len(tx.Outputs); i++ {
 		out := tx.Outputs[i]
-		t.outputs = append(t.outputs, output{publicKey: shortenPK(utils.BytesToHex(out.PublicKey)), value: out.Value})
+		t.outputs = append(t.outputs, output{publicKey: shortenPK(utils.BytesToHex(utils.GetLockingScript(out))), value: utils.FormatAmount(out.Value)})
 	}
 	return t
 }
@@ -188,7 +188,7 @@ func txToCb(tx *model.Transaction) coinbaseTransaction {
 
 	for i := 0; i < len(tx.Outputs); i++ {
 		out := tx.Outputs[i]
-		cb.outputs = append(cb.outputs, output{publicKey: shortenPK(utils.BytesToHex(out.PublicKey)), value: out.Value})
+		cb.outputs = append(cb.outputs, output{publicKey: shortenPK(utils.BytesToHex(utils.GetLockingScript(out))), value: utils.FormatAmount(out.Value)})
 	}
 
 	cb.height = tx.Height
@@ -198,8 +198,8 @@ func txToCb(tx *model.Transaction) coinbaseTransaction {
 func blockToblock(b *model.Block) block {
 	n := block{
 		hash:     shortenString(b.Hash),
-		prevHash: shortenString(b.PrevHash),
-		nounce:   b.Nounce,
+		prevHash: shortenString(b.GetHeader().GetPrevHash()),
+		nounce:   b.GetHeader().GetNounce(),
 	}
 
 	if b.Coinbase != nil {
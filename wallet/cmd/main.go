@@ -2,6 +2,8 @@ package main
 
 import (
 	"bufio"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -11,18 +13,24 @@ import (
 
 	"github.com/Luismorlan/btc_in_go/commands"
 	"github.com/Luismorlan/btc_in_go/layout"
+	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/utils"
 	"github.com/Luismorlan/btc_in_go/wallet"
 	"github.com/jroimartin/gocui"
 )
 
 var (
-	keyPath   *string
-	debugMode *bool
+	keyPath      *string
+	keyType      *string
+	debugMode    *bool
+	coinSelector *string
 )
 
 func init() {
-	keyPath = flag.String("key_path", "/tmp/mykey.pem", "RSA file path for your private key")
+	keyPath = flag.String("key_path", "/tmp/mykey.pem", "PEM file path for your private key")
+	keyType = flag.String("key_type", "ed25519", "Type of the key generated if there is no key file: rsa or ed25519.")
 	debugMode = flag.Bool("debug_mode", false, "Using debug mode will disable fancy GUI.")
+	coinSelector = flag.String("coin_selection", utils.BRANCH_AND_BOUND, "Strategy choosing coins to spend: largest_first, branch_and_bound or random_improve.")
 }
 
 // Return a gui handle if not in debug mode.
@@ -52,12 +60,21 @@ func ListenOnInput(cmd chan commands.ClientCommand, debugMode bool) *gocui.Gui {
 func main() {
 	flag.Parse()
 	fmt.Println("keyPath is", *keyPath)
+	selector, err := utils.GetCoinSelector(*coinSelector)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	t, err := utils.ParseKeyType(*keyType)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
 	cmd := make(chan commands.ClientCommand)
 	// Start listening on input.
 	g := ListenOnInput(cmd, *debugMode)
-	wallet := wallet.NewWallet(*keyPath, g)
-	wallet.Log("Wallet public key: " + wallet.GetPublicKey())
+	wallet := wallet.NewWallet(*keyPath, t, g)
+	wallet.SetCoinSelector(selector)
+	wallet.Log("Wallet address: " + wallet.GetAddress())
 
 	go HandleCommand(cmd, wallet)
 
@@ -87,19 +104,227 @@ func HandleCommand(cmd chan commands.ClientCommand, wallet *wallet.Wallet) {
 		c := <-cmd
 		switch c.Op {
 		case commands.TRANSFER:
-			aliasOrPk := c.Args[0]
-			if pk, exist := wallet.GetPKFromAlias(aliasOrPk); exist {
-				aliasOrPk = pk
+			receiver, ok := resolveAddress(wallet, c.Args[0])
+			if !ok {
+				continue
+			}
+			value, err := utils.ParseAmount(c.Args[1])
+			if err != nil {
+				wallet.Log("invalid amount: " + err.Error())
+				continue
+			}
+			amount, isFee, err := parseFeeArg(wallet, c.Args[2:])
+			if err != nil {
+				wallet.Log(err.Error())
+				continue
+			}
+			var tx *model.Transaction
+			if isFee {
+				tx, err = wallet.TransferMoney(receiver, value, amount)
+			} else {
+				tx, err = wallet.TransferMoneyWithFeeRate(receiver, value, amount)
 			}
-			value, _ := strconv.ParseFloat(c.Args[1], 64)
-			err := wallet.TransferMoney(aliasOrPk, value)
 			if err != nil {
 				wallet.Log("fail to transfer money: " + err.Error())
 				continue
 			}
-			wallet.Log(fmt.Sprintf("successfully send transaction to fullnode, receiver: %s, value: %f", aliasOrPk, value))
+			wallet.Log(fmt.Sprintf("successfully send transaction %s to fullnode, receiver: %s, value: %s", tx.Hash, receiver, utils.FormatAmount(value)))
+		case commands.MULTISIG:
+			m, _ := strconv.Atoi(c.Args[0])
+			addr, redeem, err := wallet.CreateMultiSig(m, c.Args[1:])
+			if err != nil {
+				wallet.Log("fail to create multisig address: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("%d-of-%d multisig address: %s", m, len(c.Args)-1, addr))
+			wallet.Log("Redeem script: " + utils.BytesToHex(redeem))
+		case commands.PSBT_CREATE:
+			from, ok := resolveAddress(wallet, c.Args[0])
+			if !ok {
+				continue
+			}
+			receiver, ok := resolveAddress(wallet, c.Args[1])
+			if !ok {
+				continue
+			}
+			value, err := utils.ParseAmount(c.Args[2])
+			if err != nil {
+				wallet.Log("invalid amount: " + err.Error())
+				continue
+			}
+			amount, isFee, err := parseFeeArg(wallet, c.Args[4:])
+			if err != nil {
+				wallet.Log(err.Error())
+				continue
+			}
+			var p *model.PartiallySignedTransaction
+			if isFee {
+				p, err = wallet.CreatePSBT(from, receiver, value, amount)
+			} else {
+				p, err = wallet.CreatePSBTWithFeeRate(from, receiver, value, amount)
+			}
+			if err == nil {
+				err = utils.WritePSBTFile(p, c.Args[3])
+			}
+			if err != nil {
+				wallet.Log("fail to create partially signed transaction: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("wrote transaction %s to %s, pass it to co-signers to psbt_sign", p.Tx.Hash, c.Args[3]))
+		case commands.PSBT_SIGN:
+			p, err := utils.ReadPSBTFile(c.Args[0])
+			if err != nil {
+				wallet.Log("fail to read partially signed transaction: " + err.Error())
+				continue
+			}
+			// Show where the coins go before signing.
+			for _, output := range p.Tx.Outputs {
+				addr, ok := utils.GetOutputAddress(output)
+				if !ok {
+					addr = "script " + utils.BytesToHex(utils.GetLockingScript(output))
+				}
+				wallet.Log(fmt.Sprintf("pays %s to %s", utils.FormatAmount(output.Value), addr))
+			}
+			n, err := wallet.SignPSBT(p)
+			if err == nil {
+				err = utils.WritePSBTFile(p, c.Args[0])
+			}
+			if err != nil {
+				wallet.Log("fail to sign partially signed transaction: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("signed %d inputs of transaction %s in %s", n, p.Tx.Hash, c.Args[0]))
+		case commands.PSBT_COMBINE:
+			p, err := utils.ReadPSBTFile(c.Args[0])
+			for _, path := range c.Args[1:] {
+				if err != nil {
+					break
+				}
+				var other *model.PartiallySignedTransaction
+				other, err = utils.ReadPSBTFile(path)
+				if err == nil {
+					err = utils.CombinePSBT(p, other)
+				}
+			}
+			if err == nil {
+				err = utils.WritePSBTFile(p, c.Args[0])
+			}
+			if err != nil {
+				wallet.Log("fail to combine partially signed transactions: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("combined signatures of transaction %s into %s", p.Tx.Hash, c.Args[0]))
+		case commands.PSBT_SEND:
+			p, err := utils.ReadPSBTFile(c.Args[0])
+			if err != nil {
+				wallet.Log("fail to read partially signed transaction: " + err.Error())
+				continue
+			}
+			tx, err := wallet.SendPSBT(p)
+			if err != nil {
+				wallet.Log("fail to send partially signed transaction: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("successfully send transaction %s to fullnode", tx.Hash))
+		case commands.HTLC_SECRET:
+			preimage, hash, err := utils.NewHTLCSecret()
+			if err != nil {
+				wallet.Log("fail to generate secret: " + err.Error())
+				continue
+			}
+			wallet.Log("Secret, keep it until you claim: " + utils.BytesToHex(preimage))
+			wallet.Log("Hash, share it with the other party: " + utils.BytesToHex(hash))
+		case commands.HTLC_CREATE:
+			receiver, ok := resolveAddress(wallet, c.Args[0])
+			if !ok {
+				continue
+			}
+			value, err := utils.ParseAmount(c.Args[1])
+			if err != nil {
+				wallet.Log("invalid amount: " + err.Error())
+				continue
+			}
+			hash, _ := hex.DecodeString(c.Args[2])
+			lockTime, _ := strconv.ParseInt(c.Args[3], 10, 64)
+			amount, isFee, err := parseFeeArg(wallet, c.Args[4:])
+			if err != nil {
+				wallet.Log(err.Error())
+				continue
+			}
+			addr, redeem, err := wallet.CreateHTLC(receiver, hash, lockTime)
+			if err != nil {
+				wallet.Log("fail to create contract: " + err.Error())
+				continue
+			}
+			var tx *model.Transaction
+			if isFee {
+				tx, err = wallet.TransferMoney(addr, value, amount)
+			} else {
+				tx, err = wallet.TransferMoneyWithFeeRate(addr, value, amount)
+			}
+			if err != nil {
+				wallet.Log("fail to fund contract: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("successfully send transaction %s funding contract %s with %s", tx.Hash, addr, utils.FormatAmount(value)))
+			wallet.Log("Redeem script: " + utils.BytesToHex(redeem))
+		case commands.HTLC_CLAIM, commands.HTLC_REFUND:
+			redeem, _ := hex.DecodeString(c.Args[0])
+			var preimage []byte
+			rest := c.Args[1:]
+			if c.Op == commands.HTLC_CLAIM {
+				preimage, _ = hex.DecodeString(c.Args[1])
+				rest = c.Args[2:]
+			}
+			feeRate, _, err := parseFeeArg(wallet, rest)
+			if err != nil {
+				wallet.Log(err.Error())
+				continue
+			}
+			var txs []*model.Transaction
+			if c.Op == commands.HTLC_CLAIM {
+				txs, err = wallet.ClaimHTLC(redeem, preimage, feeRate)
+			} else {
+				txs, err = wallet.RefundHTLC(redeem, feeRate)
+			}
+			if err != nil {
+				wallet.Log("fail to spend contract: " + err.Error())
+				continue
+			}
+			for _, tx := range txs {
+				wallet.Log(fmt.Sprintf("successfully send transaction %s to fullnode, value: %s", tx.Hash, utils.FormatAmount(tx.Outputs[0].Value)))
+			}
+		case commands.HTLC_PREIMAGE:
+			redeem, _ := hex.DecodeString(c.Args[0])
+			preimage, err := wallet.FindHTLCPreimage(redeem)
+			if err != nil {
+				wallet.Log("fail to find secret: " + err.Error())
+				continue
+			}
+			wallet.Log("Secret: " + utils.BytesToHex(preimage))
+		case commands.BUMP_FEE:
+			extra, err := utils.ParseAmount(c.Args[1])
+			if err != nil {
+				wallet.Log("invalid amount: " + err.Error())
+				continue
+			}
+			tx, err := wallet.BumpFee(c.Args[0], extra)
+			if err != nil {
+				wallet.Log("fail to bump fee: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("successfully send replacement transaction %s to fullnode, fee increased by %s", tx.Hash, utils.FormatAmount(extra)))
+		case commands.MIGRATE_KEY:
+			tx, err := wallet.MigrateKey(c.Args[0], model.KeyType_ED25519)
+			if err != nil {
+				wallet.Log("fail to migrate key: " + err.Error())
+				continue
+			}
+			wallet.Log(fmt.Sprintf("successfully send transaction %s moving all coins to the new key in %s, start the wallet with -key_path=%s from now on", tx.Hash, c.Args[0], c.Args[0]))
+			wallet.Log("New address: " + wallet.GetAddress())
 		case commands.MY_PK:
-			wallet.Log("\n===============DO NOT COPY THIS LINE================\n" + wallet.GetPublicKey() + "\n===============DO NOT COPY THIS LINE================")
+			wallet.Log("Address: " + wallet.GetAddress())
+			wallet.Log("Public key: " + wallet.GetPublicKey())
 		case commands.CONNECT:
 			ipAddr := c.Args[0]
 			port := c.Args[1]
@@ -110,24 +335,60 @@ func HandleCommand(cmd chan commands.ClientCommand, wallet *wallet.Wallet) {
 			}
 			wallet.Log("connected full node endpoint " + ipAddr + ":" + port)
 		case commands.GET_BALANCE:
-			v, err := wallet.GetTotalDeposit()
+			v, immature, err := wallet.GetTotalDeposit()
 			if err != nil {
 				wallet.Log("fail to get balance: " + err.Error())
 				continue
 			}
-			wallet.Log(fmt.Sprintf("your total balance is: %f", v))
+			wallet.Log(fmt.Sprintf("your total balance is: %s, immature: %s", utils.FormatAmount(v), utils.FormatAmount(immature)))
 		case commands.ALIAS:
 			wallet.SetAlias(c.Args[1], c.Args[0])
 		case commands.SHOW_ALIAS:
-			aToPk := wallet.ShowAlias()
-			if len(aToPk) == 0 {
+			aToAddr := wallet.ShowAlias()
+			if len(aToAddr) == 0 {
 				wallet.Log("no existing alias")
 			}
-			for _, pair := range aToPk {
-				wallet.Log(pair.Alias + " => " + pair.Pk)
+			for _, pair := range aToAddr {
+				wallet.Log(pair.Alias + " => " + pair.Address)
 			}
 		default:
 			wallet.Log(fmt.Sprintf("Unimplemented command: %d", c.Op))
 		}
 	}
 }
+
+// Return the address of an alias, or the address itself. Log and return false for an
+// unknown alias.
+func resolveAddress(wallet *wallet.Wallet, s string) (string, bool) {
+	if addr, exist := wallet.GetAddressFromAlias(s); exist {
+		return addr, true
+	}
+	if commands.ALIAS_REGEX.MatchString(s) {
+		wallet.Log("unknown alias: " + s)
+		return "", false
+	}
+	return s, true
+}
+
+// Parse the optional fee argument left in args. Return the fee and true for
+// "fee=AMOUNT", otherwise the fee rate of "rate=N" or estimated by the full node.
+func parseFeeArg(wallet *wallet.Wallet, args []string) (int64, bool, error) {
+	if len(args) == 1 && strings.HasPrefix(args[0], "fee=") {
+		fee, err := utils.ParseAmount(strings.TrimPrefix(args[0], "fee="))
+		if err != nil {
+			return 0, false, errors.New("invalid fee: " + err.Error())
+		}
+		return fee, true, nil
+	}
+	var feeRate int64
+	var err error
+	if len(args) == 1 {
+		feeRate, err = strconv.ParseInt(strings.TrimPrefix(args[0], "rate="), 10, 64)
+	} else {
+		feeRate, err = wallet.EstimateFeeRate()
+	}
+	if err != nil {
+		return 0, false, errors.New("fail to get fee rate: " + err.Error())
+	}
+	return feeRate, false, nil
+}
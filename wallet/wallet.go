@@ -2,18 +2,20 @@ package wallet
 
 import (
 	"context"
-	"crypto/rsa"
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
 	"github.com/Luismorlan/btc_in_go/service"
 	"github.com/Luismorlan/btc_in_go/utils"
 	"github.com/jroimartin/gocui"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/proto"
 )
 
 // User signs and sends transactions to network.
@@ -21,57 +23,89 @@ import (
 // operations are linear. No concurrent operation is supported.
 type Wallet struct {
 	// The credential (sk, pk) of this wallet.
-	keys *rsa.PrivateKey
+	keys utils.PrivateKey
 	// The client to connect to FullNode server.
 	client service.FullNodeServiceClient
 	// gRPC connection this client has.
 	conn *grpc.ClientConn
 	// The balance. Updated every Transfer and GetBalance.
 	UTXOs map[model.UTXOLite]*model.Output
-	// map from alias to public key.
+	// Coinbase outputs which can't be spent yet, they are not in UTXOs.
+	Immature map[model.UTXOLite]*model.Output
+	// map from alias to address.
 	alias map[string]string
+	// Transactions sent by this wallet and not known to be confirmed, keyed by hash. They
+	// can be replaced with a higher fee, and their inputs are not spent again.
+	pending map[string]*model.Transaction
+	// Strategy choosing which coins a transfer spends.
+	selector utils.CoinSelector
+	// Redeem scripts of multisig addresses created by this wallet, keyed by address.
+	multisig map[string][]byte
 
 	// A command fancy place to put output.
 	g *gocui.Gui
 }
 
-type AliasPkPair struct {
-	Alias string
-	Pk    string
+type AliasAddressPair struct {
+	Alias   string
+	Address string
 }
 
 // Set a alias in the map.
-func (w *Wallet) SetAlias(alias string, pk string) {
-	w.alias[alias] = pk
+func (w *Wallet) SetAlias(alias string, addr string) {
+	w.alias[alias] = addr
 }
 
-// Return "", false if not found, otherwise return pk, true
-func (w *Wallet) GetPKFromAlias(a string) (string, bool) {
-	pk, exist := w.alias[a]
-	return pk, exist
+// Return "", false if not found, otherwise return address, true
+func (w *Wallet) GetAddressFromAlias(a string) (string, bool) {
+	addr, exist := w.alias[a]
+	return addr, exist
 }
 
-func (w *Wallet) ShowAlias() []AliasPkPair {
-	res := []AliasPkPair{}
-	for a, pk := range w.alias {
-		res = append(res, AliasPkPair{Alias: a, Pk: pk})
+func (w *Wallet) ShowAlias() []AliasAddressPair {
+	res := []AliasAddressPair{}
+	for a, addr := range w.alias {
+		res = append(res, AliasAddressPair{Alias: a, Address: addr})
 	}
 	return res
 }
 
-// Return my public key in hex string.
+// Return my public key encoded by utils.EncodePublicKey in hex string.
 func (w *Wallet) GetPublicKey() string {
-	return utils.BytesToHex(utils.PublicKeyToBytes(&w.keys.PublicKey))
+	return utils.BytesToHex(utils.EncodePublicKey(w.keys.Public()))
 }
 
-func (w *Wallet) GetTotalDeposit() (float64, error) {
+// Return my address, which is what others transfer to.
+func (w *Wallet) GetAddress() string {
+	return utils.GetAddress(w.keys.Public())
+}
+
+// Return the sums of spendable and immature confirmed UTXOs in base units.
+func (w *Wallet) GetTotalDeposit() (int64, int64, error) {
 	err := w.GetBalance()
-	var v float64 = 0
 	if err != nil {
-		return v, err
+		return 0, 0, err
+	}
+	v, err := sumOutputs(w.UTXOs)
+	if err != nil {
+		return 0, 0, err
 	}
-	for _, output := range w.UTXOs {
-		v += output.GetValue()
+	immature, err := sumOutputs(w.Immature)
+	if err != nil {
+		return 0, 0, err
+	}
+	return v, immature, nil
+}
+
+// Return the total value of the outputs in base units.
+func sumOutputs(outputs map[model.UTXOLite]*model.Output) (int64, error) {
+	var v int64 = 0
+	var err error
+	for _, output := range outputs {
+		v, err = utils.AddAmount(v, output.GetValue())
+		if err != nil {
+			return 0, err
+		}
 	}
 	return v, nil
 }
@@ -124,51 +158,449 @@ func (w *Wallet) SetFullNodeConnection(ipAddr string, port string) error {
 // Blocking call to get balance of current public key. The balance is represented
 // as a list of UTXO and corresponding outputs.
 func (w *Wallet) GetBalance() error {
+	pk := utils.EncodePublicKey(w.keys.Public())
+	balance, immature, err := w.getUTXOs(&service.GetBalanceRequest{PublicKey: pk})
+	if err != nil {
+		return err
+	}
+	// Overwrite the current balance entirely.
+	w.UTXOs = balance
+	w.Immature = immature
+	return nil
+}
+
+// Blocking call to get the confirmed UTXOs the request asks for, split into spendable
+// and immature ones.
+func (w *Wallet) getUTXOs(req *service.GetBalanceRequest) (map[model.UTXOLite]*model.Output, map[model.UTXOLite]*model.Output, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	pk := utils.PublicKeyToBytes(&w.keys.PublicKey)
 	if w.client == nil || w.conn.GetState() != connectivity.Ready {
-		return errors.New("no available connection to fullnode, fullnode might shutdown or unstable network")
+		return nil, nil, errors.New("no available connection to fullnode, fullnode might shutdown or unstable network")
 	}
-	res, err := w.client.GetBalance(ctx, &service.GetBalanceRequest{PublicKey: pk})
+	res, err := w.client.GetBalance(ctx, req)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	// Create an entire new balance to overwrite the current balance.
 	balance := make(map[model.UTXOLite]*model.Output)
+	immature := make(map[model.UTXOLite]*model.Output)
 	for _, pair := range res.GetUtxoOutputPairs() {
 		utxoLite := model.UTXOLite{
 			PrevTxHash: pair.Utxo.PrevTxHash,
 			Index:      pair.Utxo.Index,
 		}
-		balance[utxoLite] = pair.Output
+		if pair.Immature {
+			immature[utxoLite] = pair.Output
+		} else {
+			balance[utxoLite] = pair.Output
+		}
 	}
-	w.UTXOs = balance
-	return nil
+	return balance, immature, nil
 }
 
-func (w *Wallet) TransferMoney(receiver string, value float64) error {
+// Transfer value in base units to the receiver address, paying fee base units. The transaction
+// opts in to replace-by-fee, so that its fee can be bumped with BumpFee while pending.
+func (w *Wallet) TransferMoney(receiver string, value int64, fee int64) (*model.Transaction, error) {
+	return w.transfer(receiver, value, func(outputs []*model.Output) (utils.CoinSelectionParams, error) {
+		return utils.CoinSelectionParams{Target: value, BaseFee: fee}, nil
+	})
+}
+
+// Transfer value in base units to the receiver, paying feeRate base units per
+// utils.FEE_RATE_BYTES bytes.
+func (w *Wallet) TransferMoneyWithFeeRate(receiver string, value int64, feeRate int64) (*model.Transaction, error) {
+	return w.transfer(receiver, value, func(outputs []*model.Output) (utils.CoinSelectionParams, error) {
+		return utils.NewCoinSelectionParams(outputs, feeRate, w.keys.Public(), w.keys)
+	})
+}
+
+// Transfer value to the receiver, spending coins chosen by the coin selector for the
+// parameters returned by params.
+func (w *Wallet) transfer(receiver string, value int64, params func(outputs []*model.Output) (utils.CoinSelectionParams, error)) (*model.Transaction, error) {
 	err := w.GetBalance()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	receiverPk, err := utils.HexToBytes(receiver)
+	output, err := utils.NewAddressOutput(value, receiver)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	output := &model.Output{
-		PublicKey: receiverPk,
-		Value:     value,
+	outputs := []*model.Output{output}
+	p, err := params(outputs)
+	if err != nil {
+		return nil, err
 	}
-	tx, err := utils.CreatePendingTransaction(w.keys, w.UTXOs, []*model.Output{output})
+	sel, err := utils.SelectCoins(w.selector, w.getSpendableUTXOs(), p)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	tx, err := utils.CreatePendingTransaction(w.keys, sel.Coins, outputs, sel.Fee)
+	if err != nil {
+		return nil, err
+	}
+	tx.Replaceable = true
+	err = utils.SignTransaction(w.keys, tx, sel.Coins)
+	if err != nil {
+		return nil, err
 	}
 	err = w.SendTransaction(tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	w.pending[tx.Hash] = tx
+	w.Log(fmt.Sprintf("transaction %s spends %d coins, fee %s, change %s", tx.Hash, len(sel.Coins), utils.FormatAmount(sel.Fee), utils.FormatAmount(sel.Change)))
+	return tx, nil
+}
+
+// Return confirmed UTXOs not spent by pending transactions. Pending transactions whose
+// inputs are no longer UTXOs are forgotten, since they are either confirmed or replaced.
+func (w *Wallet) getSpendableUTXOs() map[model.UTXOLite]*model.Output {
+	spent := make(map[model.UTXOLite]bool)
+	for hash, tx := range w.pending {
+		for _, input := range tx.Inputs {
+			if _, exist := w.UTXOs[model.UTXOLite{PrevTxHash: input.PrevTxHash, Index: input.Index}]; !exist {
+				delete(w.pending, hash)
+				break
+			}
+		}
+	}
+	for _, tx := range w.pending {
+		for _, input := range tx.Inputs {
+			spent[model.UTXOLite{PrevTxHash: input.PrevTxHash, Index: input.Index}] = true
+		}
+	}
+	utxos := make(map[model.UTXOLite]*model.Output)
+	for utxo, output := range w.UTXOs {
+		if !spent[utxo] {
+			utxos[utxo] = output
+		}
+	}
+	return utxos
+}
+
+// Set the strategy choosing which coins a transfer spends.
+func (w *Wallet) SetCoinSelector(selector utils.CoinSelector) {
+	w.selector = selector
+}
+
+// Rebroadcast the pending transaction with its fee raised by extra base units, taken
+// from the change output. The new transaction replaces the old one in the pool of full
+// nodes. Return the new transaction.
+func (w *Wallet) BumpFee(hash string, extra int64) (*model.Transaction, error) {
+	tx, exist := w.pending[hash]
+	if !exist {
+		return nil, fmt.Errorf("no pending transaction %s sent by this wallet", hash)
+	}
+	err := w.GetBalance()
+	if err != nil {
+		return nil, err
+	}
+	w.getSpendableUTXOs()
+	if _, exist := w.pending[hash]; !exist {
+		return nil, fmt.Errorf("transaction %s is already confirmed or replaced", hash)
+	}
+	bumped, err := createFeeBump(w.keys, tx, w.UTXOs, extra)
+	if err != nil {
+		return nil, err
+	}
+	err = w.SendTransaction(bumped)
+	if err != nil {
+		return nil, err
+	}
+	delete(w.pending, hash)
+	w.pending[bumped.Hash] = bumped
+	return bumped, nil
+}
+
+// Create a copy of the transaction paying extra more fee, taken from the change output
+// which is always the last one if any. spent has the outputs spent by the transaction.
+// READONLY:
+// * tx
+// * spent
+func createFeeBump(sk utils.PrivateKey, tx *model.Transaction, spent map[model.UTXOLite]*model.Output, extra int64) (*model.Transaction, error) {
+	if extra <= 0 {
+		return nil, fmt.Errorf("fee increase must be positive, got %s", utils.FormatAmount(extra))
+	}
+	bumped := proto.Clone(tx).(*model.Transaction)
+	change := bumped.Outputs[len(bumped.Outputs)-1]
+	if !utils.IsPaidTo(change, utils.EncodePublicKey(sk.Public())) {
+		return nil, errors.New("transaction has no change output to pay the fee from")
+	}
+	if change.Value < extra {
+		return nil, fmt.Errorf("insufficient change: %s, need %s", utils.FormatAmount(change.Value), utils.FormatAmount(extra))
+	}
+	change.Value -= extra
+	bumped.Replaceable = true
+	err := utils.SignTransaction(sk, bumped, spent)
+	if err != nil {
+		return nil, err
+	}
+	return bumped, nil
+}
+
+// Move all spendable coins to a new key of keyType, which is written to path, and use the
+// new key from now on. This is how funds of an old RSA key file are moved to an Ed25519
+// key. Immature coins stay with the old key, which is left untouched.
+func (w *Wallet) MigrateKey(path string, keyType model.KeyType) (*model.Transaction, error) {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s already exists, refuse to overwrite it", path)
+	}
+	err := w.GetBalance()
+	if err != nil {
+		return nil, err
+	}
+	feeRate, err := w.EstimateFeeRate()
+	if err != nil {
+		return nil, err
+	}
+	sk, err := utils.GenerateKey(keyType, utils.DEFAULT_RSA_LEN)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := createSweep(w.keys, w.getSpendableUTXOs(), sk.Public(), feeRate)
+	if err != nil {
+		return nil, err
+	}
+	// The new key must be safe before any coin is sent to it.
+	utils.WritePrivateKeyToFile(sk, path)
+	err = w.SendTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(w.Immature) > 0 {
+		w.Log(fmt.Sprintf("%d immature coins are left with the old key", len(w.Immature)))
+	}
+	w.keys = sk
+	w.UTXOs = make(map[model.UTXOLite]*model.Output)
+	w.Immature = make(map[model.UTXOLite]*model.Output)
+	w.pending = make(map[string]*model.Transaction)
+	return tx, nil
+}
+
+// Create a transaction spending all utxos to pk, paying feeRate base units per
+// utils.FEE_RATE_BYTES bytes.
+// READONLY:
+// * utxos
+func createSweep(sk utils.PrivateKey, utxos map[model.UTXOLite]*model.Output, pk utils.PublicKey, feeRate int64) (*model.Transaction, error) {
+	if len(utxos) == 0 {
+		return nil, errors.New("no spendable coin to move")
+	}
+	p, err := utils.NewCoinSelectionParams([]*model.Output{utils.NewOutput(0, pk)}, feeRate, pk, sk)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, output := range utxos {
+		total, err = utils.AddAmount(total, output.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fee := p.BaseFee + int64(len(utxos))*p.InputFee
+	if total <= fee {
+		return nil, fmt.Errorf("balance %s doesn't cover fee %s", utils.FormatAmount(total), utils.FormatAmount(fee))
+	}
+	tx, err := utils.CreatePendingTransaction(sk, utxos, []*model.Output{utils.NewOutput(total-fee, pk)}, fee)
+	if err != nil {
+		return nil, err
+	}
+	tx.Replaceable = true
+	err = utils.SignTransaction(sk, tx, utxos)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Create a multisig address requiring m signatures by different ones of the public keys,
+// which are in hex encoded by utils.EncodePublicKey. The wallet remembers its redeem
+// script to spend from it with CreatePSBT. Return the address and the redeem script.
+func (w *Wallet) CreateMultiSig(m int, pks []string) (string, []byte, error) {
+	keys := [][]byte{}
+	for _, pk := range pks {
+		key, err := utils.HexToBytes(pk)
+		if err != nil {
+			return "", nil, err
+		}
+		keys = append(keys, key)
+	}
+	redeem, err := utils.CreateMultiSigScript(m, keys)
+	if err != nil {
+		return "", nil, err
+	}
+	addr := utils.GetScriptAddress(redeem)
+	w.multisig[addr] = redeem
+	return addr, redeem, nil
+}
+
+// Create a partially signed transaction paying value base units from the multisig
+// address to the receiver address, paying fee base units. Change goes back to the
+// multisig address. It is signed by this wallet if its key is one of the multisig keys.
+func (w *Wallet) CreatePSBT(from string, receiver string, value int64, fee int64) (*model.PartiallySignedTransaction, error) {
+	return w.createPSBT(from, receiver, value, func(outputs []*model.Output, redeem []byte) (utils.CoinSelectionParams, error) {
+		return utils.CoinSelectionParams{Target: value, BaseFee: fee}, nil
+	})
+}
+
+// Like CreatePSBT, but paying feeRate base units per utils.FEE_RATE_BYTES bytes.
+func (w *Wallet) CreatePSBTWithFeeRate(from string, receiver string, value int64, feeRate int64) (*model.PartiallySignedTransaction, error) {
+	return w.createPSBT(from, receiver, value, func(outputs []*model.Output, redeem []byte) (utils.CoinSelectionParams, error) {
+		return utils.NewMultiSigCoinSelectionParams(outputs, feeRate, redeem)
+	})
+}
+
+func (w *Wallet) createPSBT(from string, receiver string, value int64, params func(outputs []*model.Output, redeem []byte) (utils.CoinSelectionParams, error)) (*model.PartiallySignedTransaction, error) {
+	redeem, exist := w.multisig[from]
+	if !exist {
+		return nil, fmt.Errorf("unknown multisig address %s, create it with multisig first", from)
+	}
+	change, err := utils.NewAddressOutput(0, from)
+	if err != nil {
+		return nil, err
+	}
+	utxos, _, err := w.getUTXOs(&service.GetBalanceRequest{Script: change.Script})
+	if err != nil {
+		return nil, err
+	}
+	output, err := utils.NewAddressOutput(value, receiver)
+	if err != nil {
+		return nil, err
+	}
+	outputs := []*model.Output{output}
+	p, err := params(outputs, redeem)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := utils.SelectCoins(w.selector, utxos, p)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := utils.CreateUnsignedTransaction(sel.Coins, outputs, sel.Fee, change)
+	if err != nil {
+		return nil, err
+	}
+	spent := []*model.Output{}
+	redeems := [][]byte{}
+	for _, input := range tx.Inputs {
+		spent = append(spent, sel.Coins[model.UTXOLite{PrevTxHash: input.PrevTxHash, Index: input.Index}])
+		redeems = append(redeems, redeem)
+	}
+	psbt, err := utils.NewPSBT(tx, spent, redeems)
+	if err != nil {
+		return nil, err
+	}
+	// Nothing to sign if this wallet only coordinates the co-signers.
+	utils.SignPSBT(w.keys, psbt)
+	return psbt, nil
+}
+
+// Sign the inputs of the partially signed transaction this wallet's key can sign.
+// Return the number of inputs signed.
+// MUTABLE:
+// * p
+func (w *Wallet) SignPSBT(p *model.PartiallySignedTransaction) (int, error) {
+	return utils.SignPSBT(w.keys, p)
+}
+
+// Send the transaction of the partially signed transaction, which must have enough
+// signatures for every input.
+// READONLY:
+// * p
+func (w *Wallet) SendPSBT(p *model.PartiallySignedTransaction) (*model.Transaction, error) {
+	tx, err := utils.FinalizePSBT(p)
+	if err != nil {
+		return nil, err
+	}
+	err = w.SendTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Create a hash time locked contract paying to the receiver address once the secret whose
+// SHA256 is hash is revealed, or back to this wallet from lockTime on. It is funded by a
+// transfer to the returned address. Return the address and the redeem script, which the
+// receiver needs to claim the coins.
+func (w *Wallet) CreateHTLC(receiver string, hash []byte, lockTime int64) (string, []byte, error) {
+	redeem, err := utils.CreateHTLCScript(hash, receiver, lockTime, w.GetAddress())
+	if err != nil {
+		return "", nil, err
+	}
+	return utils.GetScriptAddress(redeem), redeem, nil
+}
+
+// Claim the coins of the hash time locked contract with its secret, paying feeRate base
+// units per utils.FEE_RATE_BYTES bytes. Return a transaction for each output funding it.
+func (w *Wallet) ClaimHTLC(redeem []byte, preimage []byte, feeRate int64) ([]*model.Transaction, error) {
+	if preimage == nil {
+		return nil, errors.New("secret is missing")
+	}
+	return w.spendHTLC(redeem, preimage, feeRate)
+}
+
+// Take back the coins of the hash time locked contract created by this wallet, which the
+// full node only accepts from its lock time on.
+func (w *Wallet) RefundHTLC(redeem []byte, feeRate int64) ([]*model.Transaction, error) {
+	return w.spendHTLC(redeem, nil, feeRate)
+}
+
+func (w *Wallet) spendHTLC(redeem []byte, preimage []byte, feeRate int64) ([]*model.Transaction, error) {
+	locking := script.PayToScriptHash(utils.HashPublicKey(redeem))
+	utxos, _, err := w.getUTXOs(&service.GetBalanceRequest{Script: locking})
+	if err != nil {
+		return nil, err
+	}
+	if len(utxos) == 0 {
+		return nil, errors.New("no confirmed coin is locked by the contract")
+	}
+	txs := []*model.Transaction{}
+	for utxo, output := range utxos {
+		tx, err := utils.CreateHTLCSpend(w.keys, utxo, output, redeem, preimage, feeRate)
+		if err != nil {
+			return nil, err
+		}
+		err = w.SendTransaction(tx)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// Return the secret of the hash time locked contract once the receiver has revealed it
+// by claiming the coins, which is how the other side of an atomic swap learns it.
+func (w *Wallet) FindHTLCPreimage(redeem []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if w.client == nil || w.conn.GetState() != connectivity.Ready {
+		return nil, errors.New("no available connection to fullnode, fullnode might shutdown or unstable network")
+	}
+	res, err := w.client.GetScriptSpends(ctx, &service.GetScriptSpendsRequest{Script: script.PayToScriptHash(utils.HashPublicKey(redeem))})
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range res.Txs {
+		if preimage, ok := utils.ExtractHTLCPreimage(tx, redeem); ok {
+			return preimage, nil
+		}
+	}
+	return nil, errors.New("the contract is not claimed yet")
+}
+
+// Ask the full node for the fee rate a new transaction should pay, in base units per
+// utils.FEE_RATE_BYTES bytes.
+func (w *Wallet) EstimateFeeRate() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if w.client == nil || w.conn.GetState() != connectivity.Ready {
+		return 0, errors.New("no available connection to fullnode, fullnode might shutdown or unstable network")
+	}
+	res, err := w.client.EstimateFee(ctx, &service.EstimateFeeRequest{})
+	if err != nil {
+		return 0, err
+	}
+	return res.FeeRate, nil
 }
 
 func (w *Wallet) SendTransaction(tx *model.Transaction) error {
@@ -200,14 +632,18 @@ func (w *Wallet) Log(s string) {
 	})
 }
 
-// Create a new wallet from given credentials.
-func NewWallet(path string, g *gocui.Gui) *Wallet {
+// Create a new wallet from given credentials, or a new key of keyType if there is no key
+// file at path.
+func NewWallet(path string, keyType model.KeyType, g *gocui.Gui) *Wallet {
 	wallet := &Wallet{
-		UTXOs: make(map[model.UTXOLite]*model.Output),
-		alias: make(map[string]string),
-		// TODO: refactor this into a client config.
-		keys: utils.ParseKeyFile(path, 304),
-		g:    g,
+		UTXOs:    make(map[model.UTXOLite]*model.Output),
+		Immature: make(map[model.UTXOLite]*model.Output),
+		alias:    make(map[string]string),
+		pending:  make(map[string]*model.Transaction),
+		multisig: make(map[string][]byte),
+		selector: utils.BranchAndBoundSelector{Fallback: utils.NewRandomImproveSelector()},
+		keys:     utils.ParseKeyFile(path, keyType, utils.DEFAULT_RSA_LEN),
+		g:        g,
 	}
 
 	return wallet
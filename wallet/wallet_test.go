@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/Luismorlan/btc_in_go/model"
+	"github.com/Luismorlan/btc_in_go/script"
 	"github.com/Luismorlan/btc_in_go/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,7 +23,7 @@ func GetTestWallet() Wallet {
 	}
 
 	return Wallet{
-		keys: privateKey,
+		keys: utils.RSAPrivateKey{Key: privateKey},
 		UTXOs: map[model.UTXOLite]*model.Output{
 			utxos: &output,
 		},
@@ -39,26 +40,76 @@ func TestCreatePendingTransaction(t *testing.T) {
 		},
 	}
 
-	actualTx, _ := utils.CreatePendingTransaction(testWallet.keys, testWallet.UTXOs, testOutputs)
+	actualTx, _ := utils.CreatePendingTransaction(testWallet.keys, testWallet.UTXOs, testOutputs, 0)
 
-	actualSignature := actualTx.Inputs[0].Signature
+	insts, err := script.Parse(actualTx.Inputs[0].Script)
+	assert.Nil(t, err)
+	actualSignature := insts[0].Data
 
 	expectedInput := &model.Input{
 		PrevTxHash: "2334ad",
 		Index:      5,
 	}
-	selfOutput := &model.Output{
-		Value:     40,
-		PublicKey: utils.PublicKeyToBytes(&testWallet.keys.PublicKey),
-	}
+	selfOutput := utils.NewOutput(40, testWallet.keys.Public())
 	expectedOutputs := testOutputs
 	expectedOutputs = append(expectedOutputs, selfOutput)
 
 	expectedPendingTx := model.Transaction{
 		Inputs:  []*model.Input{expectedInput},
 		Outputs: expectedOutputs,
+		Version: utils.TX_VERSION,
+	}
+	spent := testWallet.UTXOs[model.UTXOLite{PrevTxHash: "2334ad", Index: 5}]
+	expectedMsg, _ := utils.GetSignatureData(&expectedPendingTx, 0, spent, utils.SIGHASH_ALL)
+
+	sig, hashType, err := utils.SplitSignature(actualSignature)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(utils.SIGHASH_ALL), hashType)
+	assert.True(t, testWallet.keys.Public().Verify(expectedMsg, sig))
+}
+
+func TestCreateFeeBump(t *testing.T) {
+	testWallet := GetTestWallet()
+	tx, err := utils.CreatePendingTransaction(testWallet.keys, testWallet.UTXOs, []*model.Output{}, 0)
+	assert.Nil(t, err)
+
+	bumped, err := createFeeBump(testWallet.keys, tx, testWallet.UTXOs, 20)
+	assert.Nil(t, err)
+	assert.NotEqual(t, tx.Hash, bumped.Hash)
+	assert.True(t, bumped.Replaceable)
+	assert.Equal(t, int64(30), bumped.Outputs[0].Value)
+	// The original transaction is not modified.
+	assert.Equal(t, int64(50), tx.Outputs[0].Value)
+
+	l := model.NewLedger()
+	for utxo, output := range testWallet.UTXOs {
+		l.L[utxo] = output
 	}
-	expectedMsg, _ := utils.GetInputDataToSignByIndex(&expectedPendingTx, 0)
+	assert.Nil(t, utils.IsValidTransaction(bumped, l, utils.ValidationContext{}))
+
+	_, err = createFeeBump(testWallet.keys, tx, testWallet.UTXOs, 51)
+	assert.NotNil(t, err)
+	_, err = createFeeBump(testWallet.keys, tx, testWallet.UTXOs, 0)
+	assert.NotNil(t, err)
+}
+
+func TestCreateSweep(t *testing.T) {
+	testWallet := GetTestWallet()
+	l := model.NewLedger()
+	for utxo, output := range testWallet.UTXOs {
+		l.L[utxo] = output
+	}
+	sk, err := utils.GenerateKey(model.KeyType_ED25519, 0)
+	assert.Nil(t, err)
+
+	// The RSA coin moves to the Ed25519 key as a whole.
+	tx, err := createSweep(testWallet.keys, testWallet.UTXOs, sk.Public(), 0)
+	assert.Nil(t, err)
+	assert.Nil(t, utils.IsValidTransaction(tx, l, utils.ValidationContext{}))
+	assert.Equal(t, []*model.Output{utils.NewOutput(50, sk.Public())}, tx.Outputs)
 
-	assert.True(t, utils.Verify(expectedMsg, &testWallet.keys.PublicKey, actualSignature))
+	_, err = createSweep(testWallet.keys, testWallet.UTXOs, sk.Public(), 1000)
+	assert.NotNil(t, err)
+	_, err = createSweep(testWallet.keys, map[model.UTXOLite]*model.Output{}, sk.Public(), 0)
+	assert.NotNil(t, err)
 }